@@ -0,0 +1,149 @@
+package board
+
+import "math/bits"
+
+// bitset has one bit per square of a board up to MaxSize x MaxSize, square (x, y)
+// of a board of width w is bit y*w+x. It is the board package's own take on the
+// builtinai bitboards, which only come in 6x6 and 8x8.
+type bitset [MaxSize * MaxSize / 64]uint64
+
+func (b bitset) has(i int) bool {
+	return b[i>>6]&(1<<(i&63)) != 0
+}
+
+func (b *bitset) set(i int) {
+	b[i>>6] |= 1 << (i & 63)
+}
+
+func (b *bitset) clear(i int) {
+	b[i>>6] &^= 1 << (i & 63)
+}
+
+func (b bitset) and(o bitset) bitset {
+	for i := range b {
+		b[i] &= o[i]
+	}
+	return b
+}
+
+func (b bitset) or(o bitset) bitset {
+	for i := range b {
+		b[i] |= o[i]
+	}
+	return b
+}
+
+func (b bitset) andNot(o bitset) bitset {
+	for i := range b {
+		b[i] &^= o[i]
+	}
+	return b
+}
+
+func (b bitset) isEmpty() bool {
+	return b == bitset{}
+}
+
+func (b bitset) count() int {
+	count := 0
+	for _, w := range b {
+		count += bits.OnesCount64(w)
+	}
+	return count
+}
+
+// shift moves every bit n places up, or down when n is negative
+func (b bitset) shift(n int) bitset {
+	var r bitset
+	if n >= 0 {
+		words, off := n>>6, uint(n&63)
+		for i := len(b) - 1; i >= words; i-- {
+			r[i] = b[i-words] << off
+			if off > 0 && i-words > 0 {
+				r[i] |= b[i-words-1] >> (64 - off)
+			}
+		}
+	} else {
+		n = -n
+		words, off := n>>6, uint(n&63)
+		for i := 0; i < len(b)-words; i++ {
+			r[i] = b[i+words] >> off
+			if off > 0 && i+words+1 < len(b) {
+				r[i] |= b[i+words+1] << (64 - off)
+			}
+		}
+	}
+	return r
+}
+
+// geometry is what the bitsets of a board of width x height need to know about its shape
+type geometry struct {
+	width, height int
+
+	// the board fits in one word, the bitsets are handled as uint64 (see mobility64)
+	small bool
+
+	// every square of the board
+	all bitset
+
+	// a bitset shifted by steps[i] moves every square one step along Directions[i],
+	// masks[i] then drops those that went off the board or wrapped to the other side
+	steps [8]int
+	masks [8]bitset
+}
+
+// geometries[w][h] is shared by every board of width w and height h
+var geometries [MaxSize + 1][MaxSize + 1]*geometry
+
+func init() {
+	for w := 1; w <= MaxSize; w++ {
+		for h := 1; h <= MaxSize; h++ {
+			geometries[w][h] = newGeometry(w, h)
+		}
+	}
+}
+
+func newGeometry(width, height int) *geometry {
+	g := &geometry{width: width, height: height, small: width*height <= 64}
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			g.all.set(y*width + x)
+		}
+	}
+	for i, d := range Directions {
+		g.steps[i] = d[1]*width + d[0]
+		for x := 0; x < width; x++ {
+			for y := 0; y < height; y++ {
+				if x-d[0] >= 0 && x-d[0] < width && y-d[1] >= 0 && y-d[1] < height {
+					g.masks[i].set(y*width + x)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// mobility64 is Board.mobility for boards of one word
+func (g *geometry) mobility64(own, other, empty uint64) uint64 {
+	var moves uint64
+	for dir, n := range g.steps {
+		mask := g.masks[dir][0]
+		step := func(b uint64) uint64 {
+			if n > 0 {
+				return b << uint(n) & mask
+			}
+			return b >> uint(-n) & mask
+		}
+		line := step(own) & other
+		for next := line | step(line)&other; next != line; next = line | step(line)&other {
+			line = next
+		}
+		moves |= step(line) & empty
+	}
+	return moves
+}
+
+// step moves every square of b one step along Directions[dir]
+func (g *geometry) step(b bitset, dir int) bitset {
+	return b.shift(g.steps[dir]).and(g.masks[dir])
+}
@@ -1,73 +1,193 @@
 package board
 
-type Board [][]Color
+import "fmt"
 
-func NewBoard(size int) Board {
-	realSize := size + 2
-	bd := make(Board, realSize)
-	for i := range bd {
-		bd[i] = make([]Color, realSize)
+// the playable board sizes, every even size in between is supported,
+// and for each side of a rectangular board
+const (
+	MinSize = 4
+	MaxSize = 16
+)
+
+// Board is the playing area, usually square but it may be any rectangle (see NewRectBoard).
+// Squares inside it can be blocked with BORDER, nobody can play there and lines of discs
+// stop at them as they do at the edge.
+type Board struct {
+	geo *geometry
+
+	// the squares of every disc color and the blocked ones, see bitset
+	black, white, red, blue, blocked bitset
+
+	// zobrist key of the discs, kept up to date by Assign
+	key uint64
+}
+
+// ValidSize reports whether a board of size x size can be played
+func ValidSize(size int) bool {
+	return size >= MinSize && size <= MaxSize && size%2 == 0
+}
+
+// SizeFromLen returns the board size described by a board string
+// of length n, or 0 if no valid board has that many squares
+func SizeFromLen(n int) int {
+	for size := MinSize; size <= MaxSize; size += 2 {
+		if size*size == n {
+			return size
+		}
 	}
-	for i := range bd {
-		bd[i][0] = BORDER
-		bd[0][i] = BORDER
-		bd[realSize-1][i] = BORDER
-		bd[i][realSize-1] = BORDER
+	return 0
+}
+
+// Opening is an arrangement of the four discs in the center of a new board
+type Opening int
+
+const (
+	Diagonal Opening = iota // the usual start, the same colors on a diagonal
+	Parallel                // the same colors side by side in a column
+)
+
+func (o Opening) String() string {
+	switch o {
+	case Diagonal:
+		return "diagonal"
+	case Parallel:
+		return "parallel"
+	default:
+		return "unknown"
 	}
+}
+
+func NewBoard(size int) *Board {
+	return NewBoardWith(size, Diagonal)
+}
+
+// NewBoardWith returns a new board with the center discs arranged as o.
+// Any other position can be set up with ParseBoard, or by playing a
+// transcript from a new board with ParseTranscript.
+func NewBoardWith(size int, o Opening) *Board {
+	return newRectBoardWith(size, size, o)
+}
 
-	bd[realSize/2-1][realSize/2-1] = WHITE
-	bd[realSize/2][realSize/2] = WHITE
-	bd[realSize/2-1][realSize/2] = BLACK
-	bd[realSize/2][realSize/2-1] = BLACK
+// NewRectBoard returns a new board of width x height, each side must be a valid size
+func NewRectBoard(width, height int) *Board {
+	return newRectBoardWith(width, height, Diagonal)
+}
 
+func newRectBoardWith(width, height int, o Opening) *Board {
+	bd := newEmptyBoard(width, height)
+	cx, cy := width/2-1, height/2-1
+	switch o {
+	case Parallel:
+		bd.Assign(WHITE, cx, cy)
+		bd.Assign(WHITE, cx, cy+1)
+		bd.Assign(BLACK, cx+1, cy)
+		bd.Assign(BLACK, cx+1, cy+1)
+	default:
+		bd.Assign(WHITE, cx, cy)
+		bd.Assign(WHITE, cx+1, cy+1)
+		bd.Assign(BLACK, cx, cy+1)
+		bd.Assign(BLACK, cx+1, cy)
+	}
 	return bd
 }
 
-func NewBoardFromStr(s string) Board {
-	size := 8
-	if len(s) == 6*6 {
-		size = 6
+func newEmptyBoard(width, height int) *Board {
+	return &Board{geo: geometries[width][height]}
+}
+
+// NewBoardFromStr is ParseBoard for strings known to be valid, it panics on errors
+func NewBoardFromStr(s string) *Board {
+	bd, err := ParseBoard(s)
+	if err != nil {
+		panic(err)
 	}
-	bd := NewBoard(size)
-	bd.AssignBoard(s)
 	return bd
 }
 
-func (bd Board) Size() int {
-	return len(bd) - 2
+// Size returns the width, which is also the height unless the board is rectangular
+func (bd *Board) Size() int {
+	return bd.Width()
+}
+
+func (bd *Board) Width() int {
+	return bd.geo.width
+}
+
+func (bd *Board) Height() int {
+	return bd.geo.height
+}
+
+func (bd *Board) IsSquare() bool {
+	return bd.Width() == bd.Height()
+}
+
+// Irregular reports whether the board is rectangular or has blocked squares
+func (bd *Board) Irregular() bool {
+	return !bd.IsSquare() || bd.CountPieces(BORDER) != 0
+}
+
+func (bd *Board) Copy() *Board {
+	nbd := *bd
+	return &nbd
 }
 
-func (bd Board) Copy() Board {
-	nbd := make(Board, bd.Size()+2)
-	for i := range bd {
-		nbd[i] = make([]Color, bd.Size()+2)
-		copy(nbd[i], bd[i])
+// squares returns the bitset of cl, nil for NONE
+func (bd *Board) squares(cl Color) *bitset {
+	switch cl {
+	case BLACK:
+		return &bd.black
+	case WHITE:
+		return &bd.white
+	case RED:
+		return &bd.red
+	case BLUE:
+		return &bd.blue
+	case BORDER:
+		return &bd.blocked
+	default:
+		return nil
 	}
-	return nbd
 }
 
-func (bd Board) AssignBoard(bd2 string) {
-	indx := 0
-	for i := 0; i < bd.Size(); i++ {
-		for j := 0; j < bd.Size(); j++ {
-			switch bd2[indx] {
-			case '+':
-				bd.Assign(NONE, j, i)
-			case 'X':
-				bd.Assign(BLACK, j, i)
-			case 'O':
-				bd.Assign(WHITE, j, i)
-			default:
-				panic("err: " + string(bd2[indx]))
-			}
-			indx++
+func (bd *Board) discs() bitset {
+	return bd.black.or(bd.white).or(bd.red).or(bd.blue)
+}
+
+func (bd *Board) empties() bitset {
+	return bd.geo.all.andNot(bd.discs()).andNot(bd.blocked)
+}
+
+// Hash returns the zobrist key of the discs on the board, the side to move is not included
+func (bd *Board) Hash() uint64 {
+	return bd.key
+}
+
+// AssignBoard sets every square from a board string of the same size,
+// the board is left unchanged if the string is not valid
+func (bd *Board) AssignBoard(bd2 string) error {
+	w, h := bd.Width(), bd.Height()
+	if len(bd2) != w*h {
+		return fmt.Errorf("board string has %d squares, want %d for %dx%d", len(bd2), w*h, w, h)
+	}
+	cls := make([]Color, len(bd2))
+	for i := range bd2 {
+		cl, err := ColorFromChar(bd2[i])
+		if err != nil {
+			return fmt.Errorf("%v at offset %d", err, i)
 		}
+		cls[i] = cl
+	}
+	for i, cl := range cls {
+		bd.Assign(cl, i%w, i/w)
 	}
+	return nil
 }
 
-func (bd Board) String() (res string) {
-	for i := 0; i < bd.Size(); i++ {
-		for j := 0; j < bd.Size(); j++ {
+// String returns the board string, one character per square row by row:
+// '+' for empty, 'X' for black, 'O' for white, '#' for blocked, 'R' for red and 'B' for blue
+func (bd *Board) String() (res string) {
+	for i := 0; i < bd.Height(); i++ {
+		for j := 0; j < bd.Width(); j++ {
 			switch bd.AtXY(j, i) {
 			case NONE:
 				res += "+"
@@ -75,6 +195,12 @@ func (bd Board) String() (res string) {
 				res += "X"
 			case WHITE:
 				res += "O"
+			case BORDER:
+				res += "#"
+			case RED:
+				res += "R"
+			case BLUE:
+				res += "B"
 			default:
 				panic("err: " + bd.AtXY(j, i).String())
 			}
@@ -83,15 +209,15 @@ func (bd Board) String() (res string) {
 	return
 }
 
-func (bd Board) Visualize() (res string) {
+func (bd *Board) Visualize() (res string) {
 	res = "  "
-	for i := 0; i < bd.Size(); i++ {
+	for i := 0; i < bd.Width(); i++ {
 		res += string(rune('a'+i)) + " "
 	}
 	res += "\n"
-	for i := 0; i < bd.Size(); i++ {
+	for i := 0; i < bd.Height(); i++ {
 		res += string(rune('A'+i)) + " "
-		for j := 0; j < bd.Size(); j++ {
+		for j := 0; j < bd.Width(); j++ {
 			switch bd.AtXY(j, i) {
 			case NONE:
 				res += "+ "
@@ -99,6 +225,12 @@ func (bd Board) Visualize() (res string) {
 				res += "X "
 			case WHITE:
 				res += "O "
+			case BORDER:
+				res += "# "
+			case RED:
+				res += "R "
+			case BLUE:
+				res += "B "
 			}
 		}
 		res += "\n"
@@ -106,19 +238,59 @@ func (bd Board) Visualize() (res string) {
 	return
 }
 
-func (bd Board) AtPoint(p Point) Color {
-	return bd[p.X+1][p.Y+1]
+// Contains reports whether p is on the board, blocked squares included
+func (bd *Board) Contains(p Point) bool {
+	return p.X >= 0 && p.X < bd.Width() && p.Y >= 0 && p.Y < bd.Height()
+}
+
+// Block puts a blocker on (x, y), whatever was there is removed
+func (bd *Board) Block(x, y int) {
+	bd.Assign(BORDER, x, y)
+}
+
+func (bd *Board) IsBlocked(p Point) bool {
+	return bd.AtPoint(p) == BORDER
 }
 
-func (bd Board) AtXY(x, y int) Color {
-	return bd[x+1][y+1]
+func (bd *Board) AtPoint(p Point) Color {
+	return bd.AtXY(p.X, p.Y)
+}
+
+// AtXY returns the color on (x, y), it is BORDER off the board
+func (bd *Board) AtXY(x, y int) Color {
+	if x < 0 || x >= bd.Width() || y < 0 || y >= bd.Height() {
+		return BORDER
+	}
+	i := y*bd.Width() + x
+	switch {
+	case bd.black.has(i):
+		return BLACK
+	case bd.white.has(i):
+		return WHITE
+	case bd.red.has(i):
+		return RED
+	case bd.blue.has(i):
+		return BLUE
+	case bd.blocked.has(i):
+		return BORDER
+	default:
+		return NONE
+	}
 }
 
-func (bd Board) Assign(cl Color, x, y int) {
-	bd[x+1][y+1] = cl
+func (bd *Board) Assign(cl Color, x, y int) {
+	old := bd.AtXY(x, y)
+	bd.key ^= ZobristSquare(old, x, y) ^ ZobristSquare(cl, x, y)
+	i := y*bd.Width() + x
+	if s := bd.squares(old); s != nil {
+		s.clear(i)
+	}
+	if s := bd.squares(cl); s != nil {
+		s.set(i)
+	}
 }
 
-func (bd Board) PutStr(cl Color, s string) bool {
+func (bd *Board) PutStr(cl Color, s string) bool {
 	if len(s) < 2 {
 		return false
 	}
@@ -126,8 +298,8 @@ func (bd Board) PutStr(cl Color, s string) bool {
 	return bd.PutPoint(cl, p)
 }
 
-func (bd Board) PutPoint(cl Color, p Point) bool {
-	if p.X < 0 || p.X >= bd.Size() || p.Y < 0 || p.Y >= bd.Size() {
+func (bd *Board) PutPoint(cl Color, p Point) bool {
+	if !bd.Contains(p) {
 		return false
 	}
 	if bd.AtPoint(p) != NONE {
@@ -141,91 +313,210 @@ func (bd Board) PutPoint(cl Color, p Point) bool {
 	return true
 }
 
-func (bd Board) PutWithoutCheck(cl Color, p Point) {
+// Placement is what putting a disc did to the board, it is returned by Play and
+// taken back by Unplay
+type Placement struct {
+	Color Color
+	Point Point
+
+	// the flipped discs, Flips[i] lists those along Directions[i] nearest first,
+	// and From[i] the colors they had, which is always the opponent with two players
+	Flips [8][]Point
+	From  [8][]Color
+}
+
+// Flipped returns every flipped disc, direction by direction
+func (pl Placement) Flipped() []Point {
+	var all []Point
+	for _, line := range pl.Flips {
+		all = append(all, line...)
+	}
+	return all
+}
+
+// Count returns the number of flipped discs
+func (pl Placement) Count() int {
+	count := 0
+	for _, line := range pl.Flips {
+		count += len(line)
+	}
+	return count
+}
+
+// Play is PutPoint that also reports the flipped discs
+func (bd *Board) Play(cl Color, p Point) (Placement, bool) {
+	if !bd.Contains(p) {
+		return Placement{}, false
+	}
+	pl := Placement{Color: cl, Point: p}
+	if bd.AtPoint(p) != NONE {
+		return pl, false
+	}
+	counts := bd.flipCounts(cl, p)
+	for i := 0; i < 8; i++ {
+		count := counts[i]
+		for j := 1; j <= count; j++ {
+			q := NewPoint(p.X+Directions[i][0]*j, p.Y+Directions[i][1]*j)
+			pl.Flips[i] = append(pl.Flips[i], q)
+			pl.From[i] = append(pl.From[i], bd.AtPoint(q))
+		}
+	}
+	if pl.Count() == 0 {
+		return pl, false
+	}
+
+	bd.Assign(cl, p.X, p.Y)
+	for _, q := range pl.Flipped() {
+		bd.Assign(cl, q.X, q.Y)
+	}
+	return pl, true
+}
+
+// Unplay takes back a placement, it must be the last one played on the board
+func (bd *Board) Unplay(pl Placement) {
+	for i, line := range pl.Flips {
+		for j, q := range line {
+			bd.Assign(pl.From[i][j], q.X, q.Y)
+		}
+	}
+	bd.Assign(NONE, pl.Point.X, pl.Point.Y)
+}
+
+func (bd *Board) PutWithoutCheck(cl Color, p Point) {
 	bd.Assign(cl, p.X, p.Y)
 	bd.flip(cl, p)
 }
 
-var direction = [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+// Directions are the 8 (dx, dy) steps a line of discs can be flipped along
+var Directions = [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
 
-func (bd Board) IsValidPoint(cl Color, p Point) bool {
-	if bd.AtPoint(p) != NONE {
+func (bd *Board) IsValidPoint(cl Color, p Point) bool {
+	own := bd.squares(cl)
+	if own == nil || bd.AtPoint(p) != NONE {
 		return false
 	}
+	other := bd.discs().andNot(*own)
 	for i := 0; i < 8; i++ {
-		if bd.CountFlipPieces(cl, p, direction[i]) > 0 {
+		if bd.countFlips(own, &other, p, Directions[i]) > 0 {
 			return true
 		}
 	}
 	return false
 }
 
-func (bd Board) CountFlipPieces(cl Color, p Point, dir [2]int) int {
-	count := 0
-	x, y := p.X, p.Y
-	opponent := cl.Opponent()
+// mobility returns the squares cl can play
+func (bd *Board) mobility(cl Color) bitset {
+	own := bd.squares(cl)
+	if own == nil {
+		return bitset{}
+	}
+	other := bd.discs().andNot(*own)
+	empty := bd.empties()
+	if bd.geo.small {
+		return bitset{bd.geo.mobility64(own[0], other[0], empty[0])}
+	}
+	var moves bitset
+	for dir := range Directions {
+		// the lines of other discs going along dir from own ones
+		line := bd.geo.step(*own, dir).and(other)
+		for {
+			next := line.or(bd.geo.step(line, dir).and(other))
+			if next == line {
+				break
+			}
+			line = next
+		}
+		moves = moves.or(bd.geo.step(line, dir).and(empty))
+	}
+	return moves
+}
 
-	x, y = x+dir[0], y+dir[1]
-	if bd.AtXY(x, y) != opponent {
+// CountFlipPieces counts the discs of other colors flanked by cl from p along dir,
+// with two players they are the opponent's, in Rolit any color but cl flips
+func (bd *Board) CountFlipPieces(cl Color, p Point, dir [2]int) int {
+	own := bd.squares(cl)
+	if own == nil {
 		return 0
 	}
-	count++
+	other := bd.discs().andNot(*own)
+	return bd.countFlips(own, &other, p, dir)
+}
 
-	for {
-		x, y = x+dir[0], y+dir[1]
-		now := bd.AtXY(x, y)
-		if now != opponent {
-			if now == cl {
-				return count
-			} else {
-				return 0
-			}
+func (bd *Board) countFlips(own, other *bitset, p Point, dir [2]int) int {
+	w, h := bd.Width(), bd.Height()
+	count := 0
+	for x, y := p.X+dir[0], p.Y+dir[1]; x >= 0 && x < w && y >= 0 && y < h; x, y = x+dir[0], y+dir[1] {
+		i := y*w + x
+		switch {
+		case other.has(i):
+			count++
+		case own.has(i) && count > 0:
+			return count
+		default:
+			return 0
 		}
-		count++
 	}
+	return 0
 }
 
-func (bd Board) flip(cl Color, p Point) {
+// flipCounts is CountFlipPieces along every direction
+func (bd *Board) flipCounts(cl Color, p Point) (counts [8]int) {
+	own := bd.squares(cl)
+	if own == nil {
+		return
+	}
+	other := bd.discs().andNot(*own)
+	for i := range Directions {
+		counts[i] = bd.countFlips(own, &other, p, Directions[i])
+	}
+	return
+}
+
+func (bd *Board) flip(cl Color, p Point) {
+	counts := bd.flipCounts(cl, p)
 	for i := 0; i < 8; i++ {
-		if count := bd.CountFlipPieces(cl, p, direction[i]); count > 0 {
+		if count := counts[i]; count > 0 {
 			for j := 1; j <= count; j++ {
-				bd.Assign(cl, p.X+direction[i][0]*j, p.Y+direction[i][1]*j)
+				bd.Assign(cl, p.X+Directions[i][0]*j, p.Y+Directions[i][1]*j)
 			}
 		}
 	}
 }
 
-func (bd Board) AllValidPoint(cl Color) []Point {
-	var all []Point
-	for i := 0; i < bd.Size(); i++ {
-		for j := 0; j < bd.Size(); j++ {
-			p := NewPoint(i, j)
-			if bd.IsValidPoint(cl, p) {
-				all = append(all, p)
+// AllValidPoint returns the squares cl can play column by column
+func (bd *Board) AllValidPoint(cl Color) []Point {
+	moves := bd.mobility(cl)
+	if moves.isEmpty() {
+		return nil
+	}
+	all := make([]Point, 0, moves.count())
+	for i := 0; i < bd.Width(); i++ {
+		for j := 0; j < bd.Height(); j++ {
+			if moves.has(j*bd.Width() + i) {
+				all = append(all, NewPoint(i, j))
 			}
 		}
 	}
 	return all
 }
 
-func (bd Board) CountPieces(cl Color) int {
-	count := 0
-	for i := 0; i < bd.Size(); i++ {
-		for j := 0; j < bd.Size(); j++ {
-			p := bd.AtXY(i, j)
-			if p == cl {
-				count++
-			}
-		}
+func (bd *Board) CountPieces(cl Color) int {
+	if s := bd.squares(cl); s != nil {
+		return s.count()
 	}
-	return count
+	if cl == NONE {
+		return bd.empties().count()
+	}
+	return 0
 }
 
-func (bd Board) EmptyCount() int {
+func (bd *Board) EmptyCount() int {
 	return bd.CountPieces(NONE)
 }
 
-func (bd Board) Winner() Color {
+// Winner returns the side with more discs, the winner under the standard rules,
+// see Rules.Winner for the others
+func (bd *Board) Winner() Color {
 	bCount := bd.CountPieces(BLACK)
 	wCount := bd.CountPieces(WHITE)
 	if bCount > wCount {
@@ -237,14 +528,20 @@ func (bd Board) Winner() Color {
 	}
 }
 
-func (bd Board) IsOver() bool {
-	for i := 0; i < bd.Size(); i++ {
-		for j := 0; j < bd.Size(); j++ {
-			p := NewPoint(i, j)
-			if bd.IsValidPoint(BLACK, p) || bd.IsValidPoint(WHITE, p) {
-				return false
-			}
-		}
+// End returns how a game played out on bd has ended, NotOver while either side
+// can move. It is where the rules say a game is over, Game and Position go
+// through it too.
+func (bd *Board) End() EndReason {
+	switch {
+	case bd.EmptyCount() == 0:
+		return BoardFull
+	case bd.mobility(BLACK).isEmpty() && bd.mobility(WHITE).isEmpty():
+		return BothBlocked
+	default:
+		return NotOver
 	}
-	return true
+}
+
+func (bd *Board) IsOver() bool {
+	return bd.End() != NotOver
 }
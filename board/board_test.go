@@ -0,0 +1,89 @@
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestPlayUnplay(t *testing.T) {
+	// Cd flips Dd only, Ff flips Ee and Dd on the diagonal towards Cc
+	bd := NewBoardFromStr("++++++++" + "++++++++" + "++X+++++" + "+++OX+++" + "+++XO+++" + "++++++++" + "++++++++" + "++++++++")
+	pl, ok := bd.Play(BLACK, StrToPoint("Ff"))
+	if !ok || pl.Count() != 2 || len(pl.Flips[5]) != 2 || pl.Flips[5][0] != StrToPoint("Ee") || pl.Flips[5][1] != StrToPoint("Dd") {
+		t.Fatal("flips", pl, "\n", bd.Visualize())
+	}
+	if _, ok := bd.Play(BLACK, StrToPoint("Aa")); ok {
+		t.Error("Aa flips nothing")
+	}
+
+	// every placement of random games can be taken back in reverse order
+	r := rand.New(rand.NewSource(1))
+	for n := 0; n < 20; n++ {
+		bd := NewBoard(8)
+		var boards []string
+		var hashes []uint64
+		var placed []Placement
+		cl := BLACK
+		for !bd.IsOver() {
+			ps := bd.AllValidPoint(cl)
+			if len(ps) > 0 {
+				boards, hashes = append(boards, bd.String()), append(hashes, bd.Hash())
+				before := bd.CountPieces(cl)
+				pl, ok := bd.Play(cl, ps[r.Intn(len(ps))])
+				if !ok || bd.CountPieces(cl) != before+pl.Count()+1 || len(pl.Flipped()) != pl.Count() {
+					t.Fatal("play", pl, "\n", bd.Visualize())
+				}
+				placed = append(placed, pl)
+			}
+			cl = cl.Opponent()
+		}
+		for i := len(placed) - 1; i >= 0; i-- {
+			bd.Unplay(placed[i])
+			if bd.String() != boards[i] || bd.Hash() != hashes[i] {
+				t.Fatal("unplay", i, "\n", bd.Visualize())
+			}
+		}
+	}
+}
+
+func TestMobility(t *testing.T) {
+	// the bitset moves agree with walking the lines from every square, on boards of
+	// one word and more, with every color and blocked squares
+	r := rand.New(rand.NewSource(1))
+	colors := []Color{NONE, NONE, BLACK, WHITE, RED, BLUE, BORDER}
+	for n := 0; n < 200; n++ {
+		w, h := 1+r.Intn(MaxSize), 1+r.Intn(MaxSize)
+		bd := newEmptyBoard(w, h)
+		for x := 0; x < w; x++ {
+			for y := 0; y < h; y++ {
+				bd.Assign(colors[r.Intn(len(colors))], x, y)
+			}
+		}
+		for _, cl := range Seats {
+			moves := bd.AllValidPoint(cl)
+			var walked []Point
+			for x := 0; x < w; x++ {
+				for y := 0; y < h; y++ {
+					p := NewPoint(x, y)
+					if bd.AtPoint(p) != NONE {
+						continue
+					}
+					for _, d := range Directions {
+						if bd.CountFlipPieces(cl, p, d) > 0 {
+							walked = append(walked, p)
+							break
+						}
+					}
+				}
+			}
+			if len(moves) != len(walked) {
+				t.Fatalf("%v on %dx%d: %v, want %v\n%s", cl, w, h, moves, walked, bd.Layout())
+			}
+			for i := range moves {
+				if moves[i] != walked[i] {
+					t.Fatalf("%v on %dx%d: %v, want %v\n%s", cl, w, h, moves, walked, bd.Layout())
+				}
+			}
+		}
+	}
+}
@@ -7,18 +7,47 @@ const (
 	BLACK  Color = 1
 	WHITE  Color = -1
 	BORDER Color = 127
+
+	// the extra colors of Rolit, see NewRolit
+	RED  Color = 2
+	BLUE Color = 3
 )
 
+// Seats are the colors in turn order, a game of n players uses the first n
+var Seats = [4]Color{BLACK, WHITE, RED, BLUE}
+
+// Opponent returns the other side of a two player game
 func (cl Color) Opponent() Color {
 	return -1 * cl
 }
 
+// IsDisc reports whether cl is the color of a disc, not an empty or blocked square
+func (cl Color) IsDisc() bool {
+	return cl != NONE && cl != BORDER
+}
+
+// Number returns the seat of cl counted from 1 as external AIs are told,
+// 1 for black, 2 for white, 3 for red and 4 for blue, or 0 for no color
+func (cl Color) Number() int {
+	for i, seat := range Seats {
+		if seat == cl {
+			return i + 1
+		}
+	}
+	return 0
+}
+
 func (cl Color) String() string {
-	if cl == BLACK {
+	switch cl {
+	case BLACK:
 		return "black"
-	} else if cl == WHITE {
+	case WHITE:
 		return "white"
-	} else {
+	case RED:
+		return "red"
+	case BLUE:
+		return "blue"
+	default:
 		return "none"
 	}
 }
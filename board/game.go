@@ -0,0 +1,362 @@
+package board
+
+// Move is one entry of a game record, a disc put on Point or a pass
+type Move struct {
+	Color Color
+	Point Point
+	Pass  bool
+}
+
+func NewMove(cl Color, p Point) Move {
+	return Move{Color: cl, Point: p}
+}
+
+func NewPass(cl Color) Move {
+	return Move{Color: cl, Pass: true}
+}
+
+func (m Move) String() string {
+	if m.Pass {
+		return "pass"
+	}
+	return m.Point.PointToStr()
+}
+
+// EndReason tells why a game is over
+type EndReason int
+
+const (
+	NotOver     EndReason = iota
+	BoardFull             // no empty square is left
+	BothBlocked           // neither side has a valid move
+	Resignation           // the loser gave up
+	Timeout               // the loser ran out of time
+	IllegalMove           // the loser tried an invalid move
+)
+
+func (r EndReason) String() string {
+	switch r {
+	case NotOver:
+		return "not over"
+	case BoardFull:
+		return "board full"
+	case BothBlocked:
+		return "both blocked"
+	case Resignation:
+		return "resignation"
+	case Timeout:
+		return "timeout"
+	case IllegalMove:
+		return "illegal move"
+	default:
+		return "unknown"
+	}
+}
+
+// Forfeit reports whether the game was lost by a player instead of being played out
+func (r EndReason) Forfeit() bool {
+	return r == Resignation || r == Timeout || r == IllegalMove
+}
+
+// Game records how a position was reached: the starting position,
+// the side to move at the start, and every move and pass since then.
+// Undone moves are kept until a different move is played, so they can be redone.
+//
+// It is also the state machine every player goes through: the side to move
+// plays one of ValidMoves, passes when MustPass, and nothing is accepted once
+// the game has an EndReason.
+type Game struct {
+	start *Board
+	first Color
+	moves []Move
+
+	// number of moves in effect, moves[ply:] can be redone
+	ply int
+
+	bd  *Board
+	now Color
+
+	// what each of moves[:ply] did to the board, the zero Placement for passes
+	placed []Placement
+
+	// the valid moves of now and whether the game is played out, worked out once per position
+	valid    []Point
+	end      EndReason
+	analyzed bool
+
+	// how and by whom the game was lost before it was played out
+	forfeit EndReason
+	loser   Color
+}
+
+func NewGame(start *Board, first Color) *Game {
+	return &Game{
+		start: start.Copy(),
+		first: first,
+		bd:    start.Copy(),
+		now:   first,
+	}
+}
+
+// NewGameFrom starts a game from pos, its passes are not recorded
+func NewGameFrom(pos Position) *Game {
+	return NewGame(pos.Board, pos.ToMove)
+}
+
+// Position returns a copy of the current position
+func (g *Game) Position() Position {
+	pos := NewPosition(g.bd.Copy(), g.now)
+	pos.Passes = g.Passes()
+	return pos
+}
+
+// Board returns the current position, it must not be modified by the caller
+func (g *Game) Board() *Board {
+	return g.bd
+}
+
+// Start returns a copy of the starting position
+func (g *Game) Start() *Board {
+	return g.start.Copy()
+}
+
+// First returns the side to move in the starting position
+func (g *Game) First() Color {
+	return g.first
+}
+
+// Turn returns the side to move in the current position
+func (g *Game) Turn() Color {
+	return g.now
+}
+
+// Ply returns the number of moves (passes included) played to reach the current position
+func (g *Game) Ply() int {
+	return g.ply
+}
+
+// Len returns the number of recorded moves, including those that were undone
+func (g *Game) Len() int {
+	return len(g.moves)
+}
+
+// Moves returns the moves played to reach the current position
+func (g *Game) Moves() []Move {
+	res := make([]Move, g.ply)
+	copy(res, g.moves[:g.ply])
+	return res
+}
+
+// LastMove returns the move that led to the current position
+func (g *Game) LastMove() (Move, bool) {
+	if g.ply == 0 {
+		return Move{}, false
+	}
+	return g.moves[g.ply-1], true
+}
+
+// ValidMoves returns the valid moves of the side to move, none once the game is over
+func (g *Game) ValidMoves() []Point {
+	if g.IsOver() {
+		return nil
+	}
+	return g.valid
+}
+
+// MustPass reports whether the side to move has to pass
+func (g *Game) MustPass() bool {
+	return !g.IsOver() && len(g.ValidMoves()) == 0
+}
+
+// Passes returns the number of passes in a row that led to the current position
+func (g *Game) Passes() int {
+	n := 0
+	for i := g.ply - 1; i >= 0 && g.moves[i].Pass; i-- {
+		n++
+	}
+	return n
+}
+
+func (g *Game) IsOver() bool {
+	return g.End() != NotOver
+}
+
+// End returns why the game is over, NotOver while it goes on
+func (g *Game) End() EndReason {
+	if g.forfeit != NotOver {
+		return g.forfeit
+	}
+	g.analyze()
+	return g.end
+}
+
+// Winner returns the winner under r, NONE for a draw or while the game goes on
+func (g *Game) Winner(r Rules) Color {
+	switch end := g.End(); {
+	case end == NotOver:
+		return NONE
+	case end.Forfeit():
+		return g.loser.Opponent()
+	default:
+		return r.Winner(g.bd)
+	}
+}
+
+// Resign ends the game with a loss for cl, whose turn it need not be
+func (g *Game) Resign(cl Color) bool {
+	return g.lose(Resignation, cl)
+}
+
+// TimeOut ends the game with a loss for cl, who ran out of time
+func (g *Game) TimeOut(cl Color) bool {
+	return g.lose(Timeout, cl)
+}
+
+// Disqualify ends the game with a loss for cl, who tried an invalid move
+func (g *Game) Disqualify(cl Color) bool {
+	return g.lose(IllegalMove, cl)
+}
+
+func (g *Game) lose(reason EndReason, cl Color) bool {
+	if g.IsOver() || (cl != BLACK && cl != WHITE) {
+		return false
+	}
+	g.forfeit, g.loser = reason, cl
+	return true
+}
+
+// analyze works out the valid moves and whether the game is over after the position changed
+func (g *Game) analyze() {
+	if g.analyzed {
+		return
+	}
+	g.analyzed = true
+	g.valid = g.bd.AllValidPoint(g.now)
+	g.end = g.bd.End()
+}
+
+// changed is called whenever the position changes, it clears what analyze found.
+// Going back to an earlier position also takes back a forfeit.
+func (g *Game) changed() {
+	g.analyzed = false
+	g.valid = nil
+	g.forfeit, g.loser = NotOver, NONE
+}
+
+// Play puts a disc of the side to move on p, it returns false if the move is not valid
+// or the game is over
+func (g *Game) Play(p Point) bool {
+	if g.IsOver() {
+		return false
+	}
+	pl, ok := g.bd.Play(g.now, p)
+	if !ok {
+		return false
+	}
+	g.record(NewMove(g.now, p), pl)
+	return true
+}
+
+// Pass passes the turn, it is only allowed when the side to move has no valid move
+func (g *Game) Pass() bool {
+	if !g.MustPass() {
+		return false
+	}
+	g.record(NewPass(g.now), Placement{})
+	return true
+}
+
+// PlayMove applies a recorded move, it must belong to the side to move
+func (g *Game) PlayMove(m Move) bool {
+	if m.Color != g.now {
+		return false
+	}
+	if m.Pass {
+		return g.Pass()
+	}
+	return g.Play(m.Point)
+}
+
+func (g *Game) record(m Move, pl Placement) {
+	g.placed = append(g.placed, pl)
+	if g.ply < len(g.moves) && g.moves[g.ply] == m {
+		// same as the undone move, keep the rest of the line
+		g.ply++
+	} else {
+		g.moves = append(g.moves[:g.ply], m)
+		g.ply++
+	}
+	g.now = g.now.Opponent()
+	g.changed()
+}
+
+// LastPlacement returns what the move that led to the current position did to the board,
+// it returns false at the start and after a pass
+func (g *Game) LastPlacement() (Placement, bool) {
+	if m, ok := g.LastMove(); !ok || m.Pass {
+		return Placement{}, false
+	}
+	return g.placed[g.ply-1], true
+}
+
+// Undo takes back the last move
+func (g *Game) Undo() bool {
+	if g.ply == 0 {
+		return false
+	}
+	g.ply--
+	m := g.moves[g.ply]
+	if !m.Pass {
+		g.bd.Unplay(g.placed[g.ply])
+	}
+	g.placed = g.placed[:g.ply]
+	g.now = m.Color
+	g.changed()
+	return true
+}
+
+// Redo plays the next undone move again
+func (g *Game) Redo() bool {
+	if g.ply >= len(g.moves) {
+		return false
+	}
+	return g.PlayMove(g.moves[g.ply])
+}
+
+// Jump goes to the position after ply moves, anywhere between the start and the last recorded move
+func (g *Game) Jump(ply int) bool {
+	if ply < 0 || ply > len(g.moves) {
+		return false
+	}
+	g.bd = g.start.Copy()
+	g.now = g.first
+	g.placed = g.placed[:0]
+	for _, m := range g.moves[:ply] {
+		var pl Placement
+		if !m.Pass {
+			pl, _ = g.bd.Play(m.Color, m.Point)
+		}
+		g.placed = append(g.placed, pl)
+		g.now = m.Color.Opponent()
+	}
+	g.ply = ply
+	g.changed()
+	return true
+}
+
+// Hash returns the zobrist key of the current position including the side to move
+func (g *Game) Hash() uint64 {
+	return g.bd.Hash() ^ ZobristTurn(g.now)
+}
+
+// Copy returns an independent copy of the record, undone moves included
+func (g *Game) Copy() *Game {
+	ng := *g
+	ng.moves = make([]Move, len(g.moves))
+	copy(ng.moves, g.moves)
+	ng.placed = make([]Placement, len(g.placed))
+	copy(ng.placed, g.placed)
+	ng.start = g.start.Copy()
+	ng.bd = g.bd.Copy()
+	return &ng
+}
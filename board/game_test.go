@@ -0,0 +1,150 @@
+package board
+
+import "testing"
+
+func TestGameUndoRedo(t *testing.T) {
+	g := NewGame(NewBoard(8), BLACK)
+	start := g.Board().String()
+
+	var positions []string
+	for _, s := range []string{"Cd", "Ec", "Ff", "Cf"} {
+		if !g.Play(StrToPoint(s)) {
+			t.Fatal("cannot play", s, "\n", g.Board().Visualize())
+		}
+		positions = append(positions, g.Board().String())
+	}
+
+	if pl, ok := g.LastPlacement(); !ok || pl.Point != StrToPoint("Cf") || pl.Color != WHITE || pl.Count() == 0 {
+		t.Error("last placement", pl)
+	}
+	if !g.Undo() || g.Board().String() != positions[2] || g.Turn() != WHITE {
+		t.Error("undo failed\n", g.Board().Visualize())
+	}
+	if !g.Redo() || g.Board().String() != positions[3] || g.Turn() != BLACK {
+		t.Error("redo failed\n", g.Board().Visualize())
+	}
+	if g.Redo() {
+		t.Error("redo beyond the last move")
+	}
+	if !g.Jump(0) || g.Board().String() != start || g.Turn() != BLACK {
+		t.Error("jump to start failed\n", g.Board().Visualize())
+	}
+	if g.Undo() {
+		t.Error("undo before the start")
+	}
+
+	// a different move drops the undone line
+	g.Jump(1)
+	if !g.Play(StrToPoint("Ce")) || g.Len() != 2 {
+		t.Error("new line was not recorded", g.Moves())
+	}
+}
+
+func TestGamePass(t *testing.T) {
+	g := NewGame(NewBoard(8), BLACK)
+	if g.Pass() {
+		t.Error("pass with valid moves")
+	}
+
+	// white cannot play Aa, black can
+	bd := NewBoardFromStr("+OXX" + "XXXX" + "XXXX" + "XXXX")
+	g = NewGame(bd, WHITE)
+	if !g.MustPass() || g.IsOver() || g.Play(StrToPoint("Aa")) {
+		t.Error("white has to pass", g.ValidMoves())
+	}
+	if !g.Pass() || g.Turn() != BLACK || g.Passes() != 1 || len(g.ValidMoves()) != 1 {
+		t.Error("white could not pass")
+	}
+	if m, ok := g.LastMove(); !ok || !m.Pass || m.Color != WHITE {
+		t.Error("pass was not recorded", m)
+	}
+	if pos := g.Position(); pos.ToMove != BLACK || pos.Passes != 1 || pos.Hash() != g.Hash() || pos.IsOver() {
+		t.Error("position after the pass", pos)
+	}
+	if !g.Play(StrToPoint("Aa")) || g.End() != BoardFull || g.Passes() != 0 || g.Winner(Standard) != BLACK {
+		t.Error("board should be full", g.End())
+	}
+
+	// neither side can move without a white disc, passing is not a way out
+	bd = NewBoardFromStr("+++++++++++++++++++++++++++XX++++++XX+++++++++++++++++++++++++++")
+	g = NewGame(bd, WHITE)
+	if g.Pass() || g.End() != BothBlocked || g.ValidMoves() != nil {
+		t.Error("both sides are blocked", g.End())
+	}
+
+	// positions are values, playing on one leaves it unchanged
+	pos := NewGame(NewBoard(8), BLACK).Position()
+	next, pl, ok := pos.Play(StrToPoint("Cd"))
+	if !ok || next.ToMove != WHITE || pl.Count() != 1 || pos.Board.String() != NewBoard(8).String() {
+		t.Error("play on a position", next)
+	}
+	if _, ok := next.Pass(); ok || next.MustPass() {
+		t.Error("white has valid moves")
+	}
+}
+
+func TestGameCustomStart(t *testing.T) {
+	bd := NewBoardWith(4, Parallel)
+	if bd.String() != "+++++OX++OX+++++" || bd.Hash() == NewBoard(4).Hash() {
+		t.Fatal("parallel start\n", bd.Visualize())
+	}
+
+	// white moves first, the start is kept after undoing everything
+	g := NewGame(bd, WHITE)
+	if !g.Play(StrToPoint("Bd")) || g.Moves()[0].Color != WHITE || g.Turn() != BLACK {
+		t.Error("white could not start\n", g.Board().Visualize())
+	}
+	g.Jump(0)
+	if g.Board().String() != bd.String() || g.Turn() != WHITE {
+		t.Error("start was not kept\n", g.Board().Visualize())
+	}
+}
+
+func TestRulesWinner(t *testing.T) {
+	bd := NewBoardFromStr("+++++++++++++++++++++++++++XX++++++XO+++++++++++++++++++++++++++")
+	if Standard.Winner(bd) != BLACK || Anti.Winner(bd) != WHITE {
+		t.Error(Standard.Winner(bd), Anti.Winner(bd))
+	}
+	if draw := NewBoard(8); Anti.Winner(draw) != NONE {
+		t.Error("draw under anti rules")
+	}
+
+	// 3 black and 1 white disc with 60 empty squares
+	// under Anti white wins the same 63-1 by 62
+	for _, c := range []struct {
+		sc           Scoring
+		black, white int
+	}{
+		{EmptiesToWinner, 63, 1},
+		{DiscsOnly, 3, 1},
+	} {
+		if black, white := bd.FinalScore(c.sc); black != c.black || white != c.white {
+			t.Errorf("%v: got %d-%d, want %d-%d", c.sc, black, white, c.black, c.white)
+		}
+	}
+	if black, white := NewBoard(8).FinalScore(EmptiesToWinner); black != 32 || white != 32 {
+		t.Error("a draw splits the empty squares", black, white)
+	}
+}
+
+func TestGameForfeit(t *testing.T) {
+	g := NewGame(NewBoard(8), BLACK)
+	g.Play(StrToPoint("Cd"))
+	if g.Resign(NONE) || !g.Resign(BLACK) || g.End() != Resignation || g.Winner(Standard) != WHITE {
+		t.Error("black resigned", g.End(), g.Winner(Standard))
+	}
+	if g.Play(StrToPoint("Ce")) || g.TimeOut(WHITE) || g.ValidMoves() != nil {
+		t.Error("the game is over")
+	}
+	if g.Copy().End() != Resignation {
+		t.Error("copy lost the resignation")
+	}
+
+	// taking back a move takes back the forfeit
+	if !g.Undo() || g.IsOver() || len(g.ValidMoves()) != 4 {
+		t.Error("undo after resigning", g.End())
+	}
+	if !g.Disqualify(BLACK) || !g.End().Forfeit() || g.Winner(Anti) != WHITE {
+		t.Error("illegal move loses under any rules", g.End())
+	}
+}
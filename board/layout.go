@@ -0,0 +1,100 @@
+package board
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+)
+
+// A layout describes a board of any shape up to MaxSize x MaxSize, row by row in the
+// characters of a board string, with rows on their own lines or separated by '/',
+// and optionally followed by the side to move numbered as by Color.Number:
+//
+//	++++++++
+//	++#++#++
+//	+++OX+++
+//	+++XO+++
+//	++#++#++
+//	++++++++
+//	2
+
+// ParseLayout reads a layout, black is to move unless it says otherwise
+func ParseLayout(s string) (Position, error) {
+	rows := strings.FieldsFunc(s, func(r rune) bool {
+		return r == '/' || r == '\n' || r == '\r' || r == ' ' || r == '\t'
+	})
+	toMove := BLACK
+	for _, cl := range Seats {
+		if n := len(rows); n > 0 && rows[n-1] == fmt.Sprint(cl.Number()) {
+			toMove = cl
+			rows = rows[:n-1]
+			break
+		}
+	}
+
+	height := len(rows)
+	if height == 0 || height > MaxSize {
+		return Position{}, fmt.Errorf("layout has %d rows, want 1 to %d", height, MaxSize)
+	}
+	width := len(rows[0])
+	if width == 0 || width > MaxSize {
+		return Position{}, fmt.Errorf("layout rows have %d squares, want 1 to %d", width, MaxSize)
+	}
+	for i, row := range rows {
+		if len(row) != width {
+			return Position{}, fmt.Errorf("row %d of the layout has %d squares, want %d", i+1, len(row), width)
+		}
+	}
+
+	bd := newEmptyBoard(width, height)
+	if err := bd.AssignBoard(strings.Join(rows, "")); err != nil {
+		return Position{}, err
+	}
+	return NewPosition(bd, toMove), nil
+}
+
+// Layout returns the board as a layout on a single line, rows separated by '/'
+func (bd *Board) Layout() string {
+	s := bd.String()
+	rows := make([]string, 0, bd.Height())
+	for i := 0; i < len(s); i += bd.Width() {
+		rows = append(rows, s[i:i+bd.Width()])
+	}
+	return strings.Join(rows, "/")
+}
+
+// Layout returns the position as a layout on a single line, with the side to move
+func (pos Position) Layout() string {
+	return fmt.Sprintf("%s %d", pos.Board.Layout(), pos.ToMove.Number())
+}
+
+// BlockRandom blocks n empty squares chosen by r, or as many as there are. Squares
+// next to a disc are left alone so the moves of the starting position stay open.
+// It returns the number of squares blocked.
+func (bd *Board) BlockRandom(n int, r *rand.Rand) int {
+	var free []Point
+	for y := 0; y < bd.Height(); y++ {
+		for x := 0; x < bd.Width(); x++ {
+			if bd.AtXY(x, y) == NONE && !bd.nextToDisc(x, y) {
+				free = append(free, NewPoint(x, y))
+			}
+		}
+	}
+	r.Shuffle(len(free), func(i, j int) { free[i], free[j] = free[j], free[i] })
+	if n > len(free) {
+		n = len(free)
+	}
+	for _, p := range free[:n] {
+		bd.Block(p.X, p.Y)
+	}
+	return n
+}
+
+func (bd *Board) nextToDisc(x, y int) bool {
+	for _, d := range Directions {
+		if cl := bd.AtXY(x+d[0], y+d[1]); cl == BLACK || cl == WHITE {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestLayout(t *testing.T) {
+	pos, err := ParseLayout("#+++#/+OX++/+XO++/#+++# 2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	bd := pos.Board
+	if bd.Width() != 5 || bd.Height() != 4 || bd.IsSquare() || !bd.Irregular() || pos.ToMove != WHITE {
+		t.Fatal("shape", bd.Width(), bd.Height(), pos.ToMove)
+	}
+	if pos.Layout() != "#+++#/+OX++/+XO++/#+++# 2" {
+		t.Error("layout", pos.Layout())
+	}
+	if !bd.IsBlocked(NewPoint(4, 3)) || bd.Contains(NewPoint(5, 0)) || !bd.Contains(NewPoint(4, 3)) {
+		t.Error("squares")
+	}
+	// the flank towards Aa stops at the blocked corner
+	if bd.IsValidPoint(BLACK, NewPoint(0, 0)) || !bd.IsValidPoint(BLACK, NewPoint(0, 1)) {
+		t.Error("moves", bd.AllValidPoint(BLACK))
+	}
+	if Perft(bd, BLACK, 4) != 72 {
+		t.Error("perft", Perft(bd, BLACK, 4))
+	}
+
+	// a 5x4 board turns into 4x5 and back
+	for _, tr := range bd.Symmetries() {
+		if tr.swapsAxes() {
+			t.Error("symmetry", tr)
+		}
+		if bd.Transform(tr).Transform(tr.Inverse()).String() != bd.String() {
+			t.Error("inverse", tr)
+		}
+	}
+	if c, _ := bd.Canonical(); c.Width() != 5 {
+		t.Error("canonical", c.Layout())
+	}
+
+	for _, s := range []string{"", "+++/++", "+++++++++++++++++/+++++++++++++++++"} {
+		if _, err := ParseLayout(s); err == nil {
+			t.Error("accepted", s)
+		}
+	}
+}
+
+func TestBlockRandom(t *testing.T) {
+	bd := NewBoard(8)
+	if n := bd.BlockRandom(8, rand.New(rand.NewSource(1))); n != 8 || bd.CountPieces(BORDER) != 8 {
+		t.Fatal("blocked", n, bd.CountPieces(BORDER))
+	}
+	if len(bd.AllValidPoint(BLACK)) != 4 {
+		t.Error("moves", bd.AllValidPoint(BLACK))
+	}
+	if bd.BlockRandom(100, rand.New(rand.NewSource(1))) != 64-4-8-12 {
+		t.Error("free squares", bd.CountPieces(BORDER))
+	}
+}
@@ -0,0 +1,116 @@
+package board
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Two notations are understood for a square:
+//
+//	"Bc" the project's own, uppercase row then lowercase column, used by external AIs
+//	"c2" the algebraic one used by books and databases, column letter then row number
+//
+// Both name the square of column c (X = 2) on the second row (Y = 1).
+
+// PassToken is written for a pass, ParseMove also accepts "pa", "pass" and "--"
+const PassToken = "PA"
+
+// Algebraic returns p in algebraic notation, such as "c4"
+func (p Point) Algebraic() string {
+	return string(rune('a'+p.X)) + strconv.Itoa(p.Y+1)
+}
+
+func (m Move) Algebraic() string {
+	if m.Pass {
+		return PassToken
+	}
+	return m.Point.Algebraic()
+}
+
+// ParsePoint reads a square in either notation, algebraic columns may be in uppercase
+func ParsePoint(s string) (Point, error) {
+	if len(s) < 2 {
+		return Point{-1, -1}, fmt.Errorf("invalid square %q", s)
+	}
+	first := rune(s[0])
+	switch {
+	case len(s) == 2 && first >= 'A' && first < 'A'+MaxSize && s[1] >= 'a' && s[1] < 'a'+MaxSize:
+		return StrToPoint(s), nil
+	case unicode.IsLetter(first) && unicode.ToLower(first) < 'a'+MaxSize:
+		row, err := strconv.Atoi(s[1:])
+		if err != nil || row < 1 || row > MaxSize {
+			return Point{-1, -1}, fmt.Errorf("invalid row in square %q", s)
+		}
+		return NewPoint(int(unicode.ToLower(first)-'a'), row-1), nil
+	default:
+		return Point{-1, -1}, fmt.Errorf("invalid square %q", s)
+	}
+}
+
+// ParseMove reads a square in either notation or a pass
+func ParseMove(s string) (p Point, pass bool, err error) {
+	switch s {
+	case PassToken, "pa", "pass", "--":
+		return Point{-1, -1}, true, nil
+	}
+	p, err = ParsePoint(s)
+	return p, false, err
+}
+
+// ParseTranscript replays a transcript such as "f5d6c3d3c4" from a starting position.
+// Moves are in algebraic notation and may be separated by spaces. Passes are usually
+// left out, they are played whenever the side to move has no valid move, but PassToken
+// and "pass" are accepted as well.
+func ParseTranscript(start *Board, first Color, s string) (*Game, error) {
+	g := NewGame(start, first)
+	for i := 0; i < len(s); {
+		if unicode.IsSpace(rune(s[i])) {
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j == i+1 && strings.HasPrefix(s[i:], "pass") {
+			j = i + 4
+		} else if j == i+1 && j < len(s) {
+			j++ // a pass token
+		}
+		token := s[i:j]
+
+		p, pass, err := ParseMove(token)
+		if err != nil {
+			return nil, fmt.Errorf("move %d: %v", g.Ply()+1, err)
+		}
+		if g.MustPass() {
+			g.Pass()
+			if pass {
+				i = j
+				continue
+			}
+		}
+		if pass {
+			return nil, fmt.Errorf("move %d: %v cannot pass", g.Ply()+1, g.Turn())
+		}
+		if !g.Play(p) {
+			return nil, fmt.Errorf("move %d: %s is not valid for %v", g.Ply()+1, token, g.Turn())
+		}
+		i = j
+	}
+	return g, nil
+}
+
+// Transcript returns the moves played so far in algebraic notation without separators,
+// passes are left out as ParseTranscript puts them back
+func (g *Game) Transcript() string {
+	var sb strings.Builder
+	for _, m := range g.Moves() {
+		if !m.Pass {
+			sb.WriteString(m.Point.Algebraic())
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,71 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePoint(t *testing.T) {
+	cases := map[string]Point{
+		"Bc":  {2, 1},
+		"c2":  {2, 1},
+		"C2":  {2, 1},
+		"Ha":  {0, 7},
+		"a8":  {0, 7},
+		"p16": {15, 15},
+		"Pp":  {15, 15},
+	}
+	for s, want := range cases {
+		if p, err := ParsePoint(s); err != nil || p != want {
+			t.Errorf("ParsePoint(%q) = %v, %v, want %v", s, p, err, want)
+		}
+	}
+	for _, s := range []string{"", "c", "c0", "c17", "bc", "q1", "4c"} {
+		if _, err := ParsePoint(s); err == nil {
+			t.Errorf("ParsePoint(%q) should fail", s)
+		}
+	}
+	if p := NewPoint(5, 4); p.Algebraic() != "f5" || p.PointToStr() != "Ef" {
+		t.Error(p.Algebraic(), p.PointToStr())
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	const s = "f5d6c3d3c4f4f6f3e6e7"
+	g, err := ParseTranscript(NewBoard(8), BLACK, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Transcript() != s || g.Ply() != 10 {
+		t.Error(g.Transcript(), g.Ply())
+	}
+	if _, err := ParseTranscript(NewBoard(8), BLACK, "f5 d6 c3 PA"); err == nil {
+		t.Error("pass with valid moves was accepted")
+	}
+	if _, err := ParseTranscript(NewBoard(8), BLACK, "f5f5"); err == nil {
+		t.Error("invalid move was accepted")
+	}
+	if _, err := ParseTranscript(NewBoard(8), BLACK, "f5 pass d6"); err == nil || !strings.Contains(err.Error(), "cannot pass") {
+		t.Error("pass with valid moves:", err)
+	}
+
+	// black has to pass after these on 4x4, the pass may be written out
+	for _, s := range []string{"b1a1d3d4c4b4a3d2c1a2pass", "b1 a1 d3 d4 c4 b4 a3 d2 c1 a2 pass"} {
+		g, err := ParseTranscript(NewBoard(4), BLACK, s)
+		if err != nil {
+			t.Fatal(s, err)
+		}
+		if g.Turn() != WHITE || g.Passes() != 1 {
+			t.Error(s, g.Turn(), g.Passes())
+		}
+	}
+
+	// one of the shortest games, white is wiped out after 9 moves
+	g, err = ParseTranscript(NewBoard(8), BLACK, "c4 c3 c2 b4 a5 f4 g4 c5 d6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !g.Board().IsOver() || g.Board().CountPieces(WHITE) != 0 {
+		t.Error("\n", g.Board().Visualize())
+	}
+}
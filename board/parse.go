@@ -0,0 +1,62 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ColorFromChar reads one square of a board string
+func ColorFromChar(c byte) (Color, error) {
+	switch c {
+	case '+':
+		return NONE, nil
+	case 'X':
+		return BLACK, nil
+	case 'O':
+		return WHITE, nil
+	case '#':
+		return BORDER, nil
+	case 'R':
+		return RED, nil
+	case 'B':
+		return BLUE, nil
+	default:
+		return NONE, fmt.Errorf("invalid character %q", c)
+	}
+}
+
+// ParseBoard reads a board string, one character per square row by row:
+// '+' for empty, 'X' for black, 'O' for white, '#' for blocked, and 'R' for red
+// and 'B' for blue in Rolit.
+// The size is taken from the length, which must be the square of a valid size,
+// see ParseLayout for rectangular boards.
+func ParseBoard(s string) (*Board, error) {
+	size := SizeFromLen(len(s))
+	if size == 0 {
+		return nil, fmt.Errorf("invalid board length %d, it must be the square of an even size from %d to %d", len(s), MinSize, MaxSize)
+	}
+	bd := NewBoard(size)
+	if err := bd.AssignBoard(s); err != nil {
+		return nil, err
+	}
+	return bd, nil
+}
+
+// ParsePosition reads the "<board> <1|2>" format sent to external AIs,
+// where 1 means black to move and 2 means white to move, Rolit adds 3 for red and 4 for blue
+func ParsePosition(s string) (Position, error) {
+	fields := strings.Fields(s)
+	if len(fields) != 2 {
+		return Position{}, fmt.Errorf("position %q must be a board and a side to move", s)
+	}
+	bd, err := ParseBoard(fields[0])
+	if err != nil {
+		return Position{}, err
+	}
+	for _, cl := range Seats {
+		if fields[1] == fmt.Sprint(cl.Number()) {
+			return NewPosition(bd, cl), nil
+		}
+	}
+	return Position{}, fmt.Errorf("invalid side to move %q, it must be 1 to %d", fields[1], len(Seats))
+}
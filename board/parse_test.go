@@ -0,0 +1,45 @@
+package board
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseBoard(t *testing.T) {
+	for size := MinSize; size <= MaxSize; size += 2 {
+		s := NewBoard(size).String()
+		bd, err := ParseBoard(s)
+		if err != nil || bd.Size() != size || bd.String() != s {
+			t.Error(size, err)
+		}
+	}
+
+	bad := map[string]string{
+		"":                                    "length 0",
+		strings.Repeat("+", 35):               "length 35",
+		strings.Repeat("+", 18*18):            "length 324",
+		strings.Repeat("+", 35) + "x":         "'x' at offset 35",
+		"+X" + strings.Repeat("+", 33) + "\n": "'\\n' at offset 35",
+	}
+	for s, want := range bad {
+		if _, err := ParseBoard(s); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseBoard(%q): got %v, want an error with %q", s, err, want)
+		}
+	}
+}
+
+func TestParsePosition(t *testing.T) {
+	s := NewBoard(6).String()
+	pos, err := ParsePosition(s + " 2\n")
+	if err != nil || pos.ToMove != WHITE || pos.Board.String() != s || pos.String() != s+" 2" {
+		t.Error(pos, err)
+	}
+	if pos, err := ParsePosition(s + " 3"); err != nil || pos.ToMove != RED || pos.String() != s+" 3" {
+		t.Error("red", pos, err)
+	}
+	for _, bad := range []string{s, s + " 0", s + " 5", s + " 1 2", "+ 1"} {
+		if _, err := ParsePosition(bad); err == nil {
+			t.Errorf("ParsePosition(%q) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package board
+
+import "testing"
+
+func TestPerft(t *testing.T) {
+	for size, counts := range PerftCounts {
+		for d, want := range counts {
+			if want > 100000 {
+				break
+			}
+			if got := Perft(NewBoard(size), BLACK, d+1); got != want {
+				t.Errorf("%dx%d depth %d: got %d, want %d", size, size, d+1, got, want)
+			}
+		}
+	}
+}
+
+func BenchmarkPerft(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Perft(NewBoard(8), BLACK, 7)
+	}
+}
@@ -0,0 +1,79 @@
+package board
+
+import "fmt"
+
+// Position is what a player needs to choose a move: the board, the side to move
+// and how many passes in a row led to it, the game is over after two
+type Position struct {
+	Board  *Board
+	ToMove Color
+	Passes int
+}
+
+func NewPosition(bd *Board, toMove Color) Position {
+	return Position{Board: bd, ToMove: toMove}
+}
+
+// String returns the "<board> <1|2>" format sent to external AIs, the passes are left out,
+// the side to move is numbered as by Color.Number.
+// A rectangular board can't be told from its string, it is written as a layout instead.
+func (pos Position) String() string {
+	if !pos.Board.IsSquare() {
+		return pos.Layout()
+	}
+	return fmt.Sprintf("%s %d", pos.Board, pos.ToMove.Number())
+}
+
+func (pos Position) Copy() Position {
+	pos.Board = pos.Board.Copy()
+	return pos
+}
+
+// Hash returns the zobrist key of the position including the side to move
+func (pos Position) Hash() uint64 {
+	return pos.Board.Hash() ^ ZobristTurn(pos.ToMove)
+}
+
+func (pos Position) ValidMoves() []Point {
+	return pos.Board.AllValidPoint(pos.ToMove)
+}
+
+// MustPass reports whether the side to move has no valid move while the game goes on
+func (pos Position) MustPass() bool {
+	return !pos.IsOver() && len(pos.ValidMoves()) == 0
+}
+
+// End returns how the game has ended, NotOver while it goes on
+func (pos Position) End() EndReason {
+	if pos.Passes >= 2 {
+		return BothBlocked
+	}
+	return pos.Board.End()
+}
+
+func (pos Position) IsOver() bool {
+	return pos.End() != NotOver
+}
+
+// Play returns the position after the side to move puts a disc on p, pos is unchanged
+func (pos Position) Play(p Point) (Position, Placement, bool) {
+	next := pos.Copy()
+	pl, ok := next.Board.Play(pos.ToMove, p)
+	if !ok {
+		return pos, pl, false
+	}
+	next.ToMove = pos.ToMove.Opponent()
+	next.Passes = 0
+	return next, pl, true
+}
+
+// Pass returns the position after the side to move passes, which it may only do
+// when it has no valid move. The board is shared with pos.
+func (pos Position) Pass() (Position, bool) {
+	if len(pos.ValidMoves()) != 0 {
+		return pos, false
+	}
+	pos.ToMove = pos.ToMove.Opponent()
+	pos.Passes++
+	return pos, true
+}
@@ -0,0 +1,174 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Rolit is othello for 2 to 4 players. Discs of every color but the mover's are
+// flipped, the players take turns in the order of Seats, and a player who can't
+// flip anything puts a disc on any empty square next to a disc instead, so nobody
+// ever passes. The game ends when no such square is left, the most discs wins.
+
+const (
+	MinPlayers = 2
+	MaxPlayers = len(Seats)
+)
+
+// NewRolitBoard returns a new size x size board for players, the four center
+// squares hold one disc of each seat clockwise from the top left, and the last
+// one is left empty with three players
+func NewRolitBoard(size, players int) *Board {
+	bd := newEmptyBoard(size, size)
+	c := size/2 - 1
+	center := [4]Point{NewPoint(c, c), NewPoint(c+1, c), NewPoint(c+1, c+1), NewPoint(c, c+1)}
+	for i := 0; i < players && i < len(center); i++ {
+		bd.Assign(Seats[i], center[i].X, center[i].Y)
+	}
+	return bd
+}
+
+// RolitMoves returns the squares cl can play in Rolit, those that flip something,
+// or every empty square next to a disc if there is none
+func (bd *Board) RolitMoves(cl Color) []Point {
+	if all := bd.AllValidPoint(cl); len(all) > 0 {
+		return all
+	}
+	var all []Point
+	for i := 0; i < bd.Width(); i++ {
+		for j := 0; j < bd.Height(); j++ {
+			if bd.AtXY(i, j) == NONE && bd.nextToDisc(i, j) {
+				all = append(all, NewPoint(i, j))
+			}
+		}
+	}
+	return all
+}
+
+// PlayRolit is Play under the Rolit rules, the placement flips nothing when cl
+// had no move that does
+func (bd *Board) PlayRolit(cl Color, p Point) (Placement, bool) {
+	if pl, ok := bd.Play(cl, p); ok {
+		return pl, true
+	}
+	pl := Placement{Color: cl, Point: p}
+	if !bd.Contains(p) || bd.AtPoint(p) != NONE || !bd.nextToDisc(p.X, p.Y) || len(bd.AllValidPoint(cl)) > 0 {
+		return pl, false
+	}
+	bd.Assign(cl, p.X, p.Y)
+	return pl, true
+}
+
+// Rolit is a game of Rolit, the board and the placements played on it
+type Rolit struct {
+	bd      *Board
+	players []Color
+	placed  []Placement
+
+	// index of the player to move in players
+	turn int
+}
+
+// NewRolit starts a game for players on bd, black moves first.
+// It panics unless players is from MinPlayers to MaxPlayers.
+func NewRolit(bd *Board, players int) *Rolit {
+	if players < MinPlayers || players > MaxPlayers {
+		panic(fmt.Sprintf("rolit needs %d to %d players, not %d", MinPlayers, MaxPlayers, players))
+	}
+	return &Rolit{bd: bd, players: Seats[:players]}
+}
+
+// Board returns the current board, it must not be changed
+func (r *Rolit) Board() *Board {
+	return r.bd
+}
+
+func (r *Rolit) Players() []Color {
+	return r.players
+}
+
+func (r *Rolit) Turn() Color {
+	return r.players[r.turn]
+}
+
+func (r *Rolit) Ply() int {
+	return len(r.placed)
+}
+
+// Position returns the position of the player to move, the passes are always 0
+func (r *Rolit) Position() Position {
+	return NewPosition(r.bd.Copy(), r.Turn())
+}
+
+func (r *Rolit) ValidMoves() []Point {
+	return r.bd.RolitMoves(r.Turn())
+}
+
+// IsOver reports whether no disc can be put anymore
+func (r *Rolit) IsOver() bool {
+	return len(r.ValidMoves()) == 0
+}
+
+// Play puts a disc of the player to move on p and passes the turn on
+func (r *Rolit) Play(p Point) bool {
+	pl, ok := r.bd.PlayRolit(r.Turn(), p)
+	if !ok {
+		return false
+	}
+	r.placed = append(r.placed, pl)
+	r.turn = (r.turn + 1) % len(r.players)
+	return true
+}
+
+// Undo takes back the last placement
+func (r *Rolit) Undo() bool {
+	if len(r.placed) == 0 {
+		return false
+	}
+	r.bd.Unplay(r.placed[len(r.placed)-1])
+	r.placed = r.placed[:len(r.placed)-1]
+	r.turn = (r.turn + len(r.players) - 1) % len(r.players)
+	return true
+}
+
+// LastPlacement returns the last placement, ok is false before the first one
+func (r *Rolit) LastPlacement() (pl Placement, ok bool) {
+	if len(r.placed) == 0 {
+		return Placement{}, false
+	}
+	return r.placed[len(r.placed)-1], true
+}
+
+// Scores returns the discs of every player in turn order
+func (r *Rolit) Scores() []int {
+	scores := make([]int, len(r.players))
+	for i, cl := range r.players {
+		scores[i] = r.bd.CountPieces(cl)
+	}
+	return scores
+}
+
+// Winners returns the players with the most discs, more than one on a tie
+func (r *Rolit) Winners() []Color {
+	var winners []Color
+	best := -1
+	for i, score := range r.Scores() {
+		switch {
+		case score > best:
+			best, winners = score, []Color{r.players[i]}
+		case score == best:
+			winners = append(winners, r.players[i])
+		}
+	}
+	return winners
+}
+
+// Transcript returns the placements in algebraic notation, the players are
+// known from the turn order
+func (r *Rolit) Transcript() string {
+	var sb strings.Builder
+	for _, pl := range r.placed {
+		sb.WriteString(pl.Point.Algebraic())
+	}
+	return sb.String()
+}
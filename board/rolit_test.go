@@ -0,0 +1,65 @@
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestRolit(t *testing.T) {
+	// black flanks the red disc with a white one in between, both flip
+	bd := layoutBoard(t, "++++++/++XO++/++BR++/++++++/++++++/++++++")
+	if !bd.IsValidPoint(BLACK, NewPoint(4, 1)) || bd.IsValidPoint(BLACK, NewPoint(1, 1)) {
+		t.Error("moves", bd.AllValidPoint(BLACK))
+	}
+	if pl, ok := bd.PlayRolit(BLACK, NewPoint(4, 1)); !ok || pl.Count() != 1 || pl.From[4][0] != WHITE {
+		t.Error("flip", pl)
+	}
+	// red has no disc to flank with, any square next to a disc will do
+	bd = layoutBoard(t, "++++++/++XO++/++++++/++++++/++++++/++++++")
+	if _, ok := bd.PlayRolit(RED, NewPoint(0, 5)); ok {
+		t.Error("not next to a disc")
+	}
+	if pl, ok := bd.PlayRolit(RED, NewPoint(3, 2)); !ok || pl.Count() != 0 || bd.AtXY(3, 2) != RED {
+		t.Error("place", pl, "\n", bd.Visualize())
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for players := MinPlayers; players <= MaxPlayers; players++ {
+		g := NewRolit(NewRolitBoard(8, players), players)
+		start := g.Board().String()
+		for !g.IsOver() {
+			ps := g.ValidMoves()
+			if !g.Play(ps[r.Intn(len(ps))]) {
+				t.Fatal("play", ps, "\n", g.Board().Visualize())
+			}
+		}
+		if g.Ply() != 64-players || g.Board().EmptyCount() != 0 || len(g.Winners()) == 0 {
+			t.Error("end", g.Ply(), g.Scores(), g.Winners())
+		}
+		total := 0
+		for _, score := range g.Scores() {
+			total += score
+		}
+		if total != 64 {
+			t.Error("scores", g.Scores())
+		}
+		for g.Undo() {
+		}
+		if g.Board().String() != start || g.Turn() != BLACK {
+			t.Error("undo\n", g.Board().Visualize())
+		}
+	}
+
+	g := NewRolit(NewRolitBoard(4, 4), 4)
+	if w := g.Winners(); len(w) != 4 {
+		t.Error("tie", w)
+	}
+}
+
+func layoutBoard(t *testing.T, s string) *Board {
+	pos, err := ParseLayout(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pos.Board
+}
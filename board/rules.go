@@ -0,0 +1,75 @@
+package board
+
+// Rules decides who wins a finished game, the moves are the same under every rule set
+type Rules int
+
+const (
+	Standard Rules = iota // the most discs wins
+	Anti                  // the fewest discs wins
+)
+
+// AllRules lists every rule set, Standard first
+var AllRules = []Rules{Standard, Anti}
+
+func (r Rules) String() string {
+	switch r {
+	case Standard:
+		return "standard"
+	case Anti:
+		return "anti"
+	default:
+		return "unknown"
+	}
+}
+
+// Winner returns the winner of bd under r, NONE for a draw
+func (r Rules) Winner(bd *Board) Color {
+	winner := bd.Winner()
+	if r == Anti {
+		return winner.Opponent()
+	}
+	return winner
+}
+
+// Scoring decides what the empty squares of a game that ends before the board is full count for
+type Scoring int
+
+const (
+	EmptiesToWinner Scoring = iota // the tournament rule, a draw splits them
+	DiscsOnly                      // only the discs on the board count
+)
+
+// AllScorings lists every scoring, EmptiesToWinner first
+var AllScorings = []Scoring{EmptiesToWinner, DiscsOnly}
+
+func (sc Scoring) String() string {
+	switch sc {
+	case EmptiesToWinner:
+		return "empties to winner"
+	case DiscsOnly:
+		return "discs only"
+	default:
+		return "unknown"
+	}
+}
+
+// FinalScore returns the score of black and white at the end of a game on bd. With
+// EmptiesToWinner the empty squares go to the side with more discs, widening the
+// margin either way, so under Anti they go to the loser.
+func (bd *Board) FinalScore(sc Scoring) (black, white int) {
+	black, white = bd.CountPieces(BLACK), bd.CountPieces(WHITE)
+	if sc == DiscsOnly {
+		return
+	}
+	empty := bd.EmptyCount()
+	switch bd.Winner() {
+	case BLACK:
+		black += empty
+	case WHITE:
+		white += empty
+	default:
+		black += empty / 2
+		white += empty - empty/2
+	}
+	return
+}
@@ -0,0 +1,136 @@
+package board
+
+// Transform is one of the 8 symmetries of a square board
+type Transform int
+
+const (
+	Identity Transform = iota
+	Rotate90           // clockwise
+	Rotate180
+	Rotate270
+	FlipHorizontal // mirror the columns, a <-> h on 8x8
+	FlipVertical   // mirror the rows, A <-> H on 8x8
+	Transpose      // mirror along the Aa-Hh diagonal
+	AntiTranspose  // mirror along the Ah-Ha diagonal
+)
+
+// Transforms lists every symmetry, Identity first
+var Transforms = [8]Transform{
+	Identity, Rotate90, Rotate180, Rotate270,
+	FlipHorizontal, FlipVertical, Transpose, AntiTranspose,
+}
+
+func (t Transform) String() string {
+	switch t {
+	case Identity:
+		return "identity"
+	case Rotate90:
+		return "rotate 90"
+	case Rotate180:
+		return "rotate 180"
+	case Rotate270:
+		return "rotate 270"
+	case FlipHorizontal:
+		return "flip horizontal"
+	case FlipVertical:
+		return "flip vertical"
+	case Transpose:
+		return "transpose"
+	case AntiTranspose:
+		return "anti-transpose"
+	default:
+		return "unknown"
+	}
+}
+
+// Inverse returns the transform that undoes t
+func (t Transform) Inverse() Transform {
+	switch t {
+	case Rotate90:
+		return Rotate270
+	case Rotate270:
+		return Rotate90
+	default:
+		return t
+	}
+}
+
+// Transform maps p on a board of size x size
+func (p Point) Transform(t Transform, size int) Point {
+	return p.transform(t, size, size)
+}
+
+// transform maps p on a board of width x height, the board turns into
+// a height x width one when t swaps the axes
+func (p Point) transform(t Transform, width, height int) Point {
+	w, h := width-1, height-1
+	switch t {
+	case Rotate90:
+		return Point{h - p.Y, p.X}
+	case Rotate180:
+		return Point{w - p.X, h - p.Y}
+	case Rotate270:
+		return Point{p.Y, w - p.X}
+	case FlipHorizontal:
+		return Point{w - p.X, p.Y}
+	case FlipVertical:
+		return Point{p.X, h - p.Y}
+	case Transpose:
+		return Point{p.Y, p.X}
+	case AntiTranspose:
+		return Point{h - p.Y, w - p.X}
+	default:
+		return p
+	}
+}
+
+// swapsAxes reports whether t turns a width x height board into a height x width one
+func (t Transform) swapsAxes() bool {
+	switch t {
+	case Rotate90, Rotate270, Transpose, AntiTranspose:
+		return true
+	default:
+		return false
+	}
+}
+
+// Transform returns a new board with every disc and blocked square moved by t
+func (bd *Board) Transform(t Transform) *Board {
+	w, h := bd.Width(), bd.Height()
+	nbd := newEmptyBoard(w, h)
+	if t.swapsAxes() {
+		nbd = newEmptyBoard(h, w)
+	}
+	for i := 0; i < w; i++ {
+		for j := 0; j < h; j++ {
+			p := NewPoint(i, j).transform(t, w, h)
+			nbd.Assign(bd.AtXY(i, j), p.X, p.Y)
+		}
+	}
+	return nbd
+}
+
+// Symmetries returns the transforms that keep the shape of the board, all of them
+// for a square board and those that do not swap the axes for a rectangular one
+func (bd *Board) Symmetries() []Transform {
+	if bd.IsSquare() {
+		return Transforms[:]
+	}
+	return []Transform{Identity, Rotate180, FlipHorizontal, FlipVertical}
+}
+
+// Canonical returns the representative of the board's symmetry class and the transform
+// that maps the board onto it. The representative is the image with the smallest
+// zobrist key, the builtinai bitboards use the same rule so they agree on it.
+func (bd *Board) Canonical() (*Board, Transform) {
+	best, bestT := bd, Identity
+	for _, t := range bd.Symmetries()[1:] {
+		if nbd := bd.Transform(t); nbd.Hash() < best.Hash() {
+			best, bestT = nbd, t
+		}
+	}
+	if bestT == Identity {
+		best = bd.Copy()
+	}
+	return best, bestT
+}
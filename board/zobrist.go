@@ -0,0 +1,70 @@
+package board
+
+// Zobrist keys
+//
+// The key of a position is the xor of one random number per disc on the board,
+// plus zobristWhite when white is to move. The random numbers are drawn from
+// splitmix64 seeded with zobristSeed, black's squares first then white's, each
+// in row-major order on a MaxSize x MaxSize grid. A square keeps its number
+// whatever the board size, so the keys stay the same as long as the seed and the
+// drawing order do not change. A blocked square takes the black and the white
+// number at once, which no disc can. The red and blue squares of Rolit are drawn
+// after zobristWhite so adding them left the others alone. Anything stored by key (transposition tables,
+// opening books, game databases) relies on that, so never change them.
+
+const zobristSeed uint64 = 0x4f74656c6c6f2121
+
+var (
+	zobristSquares [2][MaxSize * MaxSize]uint64
+	zobristWhite   uint64
+	zobristRolit   [2][MaxSize * MaxSize]uint64
+)
+
+func init() {
+	state := zobristSeed
+	for cl := range zobristSquares {
+		for i := range zobristSquares[cl] {
+			zobristSquares[cl][i] = splitmix64(&state)
+		}
+	}
+	zobristWhite = splitmix64(&state)
+	for cl := range zobristRolit {
+		for i := range zobristRolit[cl] {
+			zobristRolit[cl][i] = splitmix64(&state)
+		}
+	}
+}
+
+func splitmix64(state *uint64) uint64 {
+	*state += 0x9e3779b97f4a7c15
+	z := *state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// ZobristSquare returns the key of a disc of cl on (x, y), it is 0 for empty squares
+func ZobristSquare(cl Color, x, y int) uint64 {
+	switch cl {
+	case BLACK:
+		return zobristSquares[0][y*MaxSize+x]
+	case WHITE:
+		return zobristSquares[1][y*MaxSize+x]
+	case BORDER:
+		return zobristSquares[0][y*MaxSize+x] ^ zobristSquares[1][y*MaxSize+x]
+	case RED:
+		return zobristRolit[0][y*MaxSize+x]
+	case BLUE:
+		return zobristRolit[1][y*MaxSize+x]
+	default:
+		return 0
+	}
+}
+
+// ZobristTurn returns the side to move component of a key
+func ZobristTurn(cl Color) uint64 {
+	if cl == WHITE {
+		return zobristWhite
+	}
+	return 0
+}
@@ -0,0 +1,30 @@
+package board
+
+import "testing"
+
+func TestZobrist(t *testing.T) {
+	g := NewGame(NewBoard(8), BLACK)
+	seen := map[uint64]string{g.Hash(): g.Board().String()}
+	for !g.Board().IsOver() {
+		valid := g.Board().AllValidPoint(g.Turn())
+		if len(valid) == 0 {
+			g.Pass()
+			continue
+		}
+		g.Play(valid[len(valid)/2])
+
+		key := g.Hash()
+		if fresh := NewBoardFromStr(g.Board().String()).Hash() ^ ZobristTurn(g.Turn()); fresh != key {
+			t.Fatal("incremental key differs from a fresh one\n", g.Board().Visualize())
+		}
+		if s, ok := seen[key]; ok && s != g.Board().String() {
+			t.Fatal("key collision\n", s, "\n", g.Board().String())
+		}
+		seen[key] = g.Board().String()
+	}
+
+	// keys must stay stable, see zobrist.go
+	if k := NewBoard(8).Hash() ^ ZobristTurn(BLACK); k != 0x86c75d57de6384fb {
+		t.Errorf("start key changed: %#x", k)
+	}
+}
@@ -1,7 +1,12 @@
 package builtinai
 
 import (
+	"context"
 	"fmt"
+	"othello/board"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -16,8 +21,6 @@ type AI8 struct {
 
 	totalValue int
 
-	// table map[bboard8]int
-
 	// phase 1 or phase 2
 	phase int
 
@@ -27,6 +30,9 @@ type AI8 struct {
 	// maximum reached depth
 	reachedDepth int
 
+	// the deepest ply a search got to, passes and the end of the game included
+	selDepth int
+
 	// traversed nodes count
 	nodes int
 
@@ -34,62 +40,217 @@ type AI8 struct {
 	level int
 
 	nodesPool pool
+
+	// under board.Anti every evaluation is negated, the AI aims for fewer discs
+	rules board.Rules
+
+	// transposition table kept from one search to the next, allocated by the
+	// first search with its share of tableMB megabytes, see tableShare
+	table   *table
+	tableMB int
+
+	// the time budget, without one the level sets the depth
+	clock clock
+
+	// pv[2*ply] is the best line found from the node at ply where the AI is to
+	// move, pv[2*ply+1] where the opponent is, -1 for a pass
+	pv [][]int
+
+	// when the running search started, and who is told what it found
+	started time.Time
+	info    func(Result)
+
+	// the AIs searching the root moves along with this one, see SetThreads
+	helpers []*AI8
 }
 
-func NewAI8(cl color, lv Level) *AI8 {
+// NewAI8 returns the built-in AI for 8x8, cl is only the color it plays until
+// the first search, which takes it from the position
+func NewAI8(cl board.Color, lv Level) *AI8 {
 	ai := AI8{
-		color: cl,
-		// table:    make(map[bboard8]int),
-		opponent: cl.reverse(),
+		color:    color(cl),
+		opponent: color(cl).reverse(),
 	}
 
 	ai.level = int(lv)
 	ai.totalValue = 13752
 	ai.nodesPool = newPool(32)
+	ai.tableMB = DEFAULT_TABLE_MB
+	ai.pv = make([][]int, 2*(SIZE8*SIZE8+2))
 
 	return &ai
 }
 
-func (ai *AI8) Move(input string) (string, error) {
-	c := make(chan string)
-	go ai.move(input, c)
-	res := <-c
-	if len(res) > 3 {
-		return "", fmt.Errorf(res)
-	}
-	return res, nil
+// Search finds the best move for the side to move of pos,
+// whatever color the AI was created with
+func (ai *AI8) Search(pos board.Position) (Result, error) {
+	return ai.SearchContext(context.Background(), pos)
 }
 
-func (ai *AI8) move(input string, c chan string) {
-	aibd := newBboard8(input)
-	ai.nodes = 0
+// SearchContext is Search stopping early when ctx is done, with the best move
+// found by then. It fails if there is none yet.
+func (ai *AI8) SearchContext(ctx context.Context, pos board.Position) (Result, error) {
+	aibd, err := bboard8FromBoard(pos.Board)
+	if err != nil {
+		return Result{}, fmt.Errorf("builtin ai: %v", err)
+	}
+	cl, err := colorOf(pos.ToMove)
+	if err != nil {
+		return Result{}, fmt.Errorf("builtin ai: %v", err)
+	}
+	ai.color, ai.opponent = cl, cl.reverse()
+	ai.nodes, ai.selDepth, ai.started = 0, 0, time.Now()
+	ctx = ai.clock.begin(ctx)
+	defer ai.clock.end()
 
 	ai.setPhase(aibd)
 	ai.setDepth()
-
+	if ai.table == nil && ai.tableMB > 0 {
+		ai.table = newTable(ai.tableShare())
+	}
 	var best node
-	// for depth := 2; depth <= ai.depth; depth += 2 {
-	best = ai.alphaBetaHelper(aibd, ai.depth)
-	// }
-	ai.printValue(best)
+	var res Result
+	budget := ai.clock.budget(aibd.emptyCount())
+	if budget > 0 {
+		best, res = ai.deepen(aibd, budget)
+		ai.clock.spend(time.Since(ai.started))
+	} else {
+		ai.table.prepare(ai.color, ai.rules)
+		best = ai.alphaBetaHelper(aibd, ai.depth)
+	}
 
-	bestPoint := point{best.loc % SIZE8, best.loc / SIZE8}
-	if !aibd.putAndCheck(ai.color, best.loc) {
-		c <- fmt.Sprintf("cannot put: %v, builtin ai %v", bestPoint, ai.color)
+	if best.loc < 0 && ctx.Err() != nil {
+		return Result{}, fmt.Errorf("builtin ai: %v", ctx.Err())
+	}
+	if best.loc < 0 || !aibd.isValidLoc(ai.color, best.loc) {
+		return Result{}, fmt.Errorf("builtin ai %v: no valid move", ai.color)
+	}
+	if budget == 0 {
+		res = ai.result(best)
+		ai.report(res)
 	}
-	c <- bestPoint.String()
+	return res, nil
 }
 
-func (ai AI8) Close() {}
-
-func (ai AI8) printValue(best node) {
+// result is what the search that found best got to, deepen calls it after every ply
+func (ai *AI8) result(best node) Result {
+	res := Result{
+		Move:     board.NewPoint(best.loc%SIZE8, best.loc/SIZE8),
+		Score:    float64(best.value),
+		Exact:    ai.phase == 2,
+		Depth:    ai.reachedDepth,
+		SelDepth: ai.selDepth,
+		Nodes:    ai.nodes,
+		Elapsed:  time.Since(ai.started),
+	}
 	if ai.phase == 1 {
-		finValue := float64(best.value) / float64(ai.totalValue) * float64(SIZE8*SIZE8)
-		fmt.Printf("built-in AI: {depth: %d, nodes: %d, value: %+.2f}\n", ai.reachedDepth, ai.nodes, finValue)
+		res.Score = float64(best.value) / float64(ai.totalValue) * float64(SIZE8*SIZE8)
+	}
+	cl := ai.color
+	for _, loc := range ai.pv[0] {
+		if loc < 0 {
+			res.PV = append(res.PV, board.NewPass(board.Color(cl)))
+		} else {
+			res.PV = append(res.PV, board.NewMove(board.Color(cl), board.NewPoint(loc%SIZE8, loc/SIZE8)))
+		}
+		cl = cl.reverse()
+	}
+	return res
+}
+
+func (ai *AI8) report(res Result) {
+	if ai.info != nil {
+		ai.info(res)
+	}
+}
+
+// Move is Search for callers that only need the move
+func (ai *AI8) Move(pos board.Position) (board.Point, error) {
+	res, err := ai.Search(pos)
+	if err != nil {
+		return board.Point{X: -1, Y: -1}, err
+	}
+	return res.Move, nil
+}
+
+// Analyze scores the moves of the side to move of pos, best first, with the
+// same line and search info as Search. Only the n best are scored exactly, the
+// rest just proven worse, n <= 0 scores every move. depth is the plies searched,
+// the depth of the level for depth <= 0, the end of the game when the empty
+// squares are no more than depth. The time budget is not used.
+func (ai *AI8) Analyze(pos board.Position, depth, n int) ([]Result, error) {
+	aibd, err := bboard8FromBoard(pos.Board)
+	if err != nil {
+		return nil, fmt.Errorf("builtin ai: %v", err)
+	}
+	cl, err := colorOf(pos.ToMove)
+	if err != nil {
+		return nil, fmt.Errorf("builtin ai: %v", err)
+	}
+	ai.color, ai.opponent = cl, cl.reverse()
+	ai.nodes, ai.selDepth, ai.started = 0, 0, time.Now()
+	ctx := ai.clock.begin(context.Background())
+	defer ai.clock.end()
+
+	empties := aibd.emptyCount()
+	if depth <= 0 {
+		ai.setPhase(aibd)
+		ai.setDepth()
+	} else if depth < empties {
+		ai.phase, ai.depth = 1, depth
 	} else {
-		finValue := best.value
-		fmt.Printf("built-in AI: {depth: %d, nodes: %d, value: %+d}\n", ai.reachedDepth, ai.nodes, finValue)
+		ai.phase, ai.depth = 2, MAXINT
+	}
+	if ai.table == nil && ai.tableMB > 0 {
+		ai.table = newTable(ai.tableShare())
+	}
+	ai.table.prepare(ai.color, ai.rules)
+
+	var results []Result
+	var values []int
+	moves := ai.sortedValidNodes(aibd, ai.color)
+	for _, m := range moves {
+		// a move has to beat the n-th best to be scored
+		alpha := MININT
+		if n > 0 && len(values) == n {
+			alpha = values[n-1]
+		}
+		tmp := aibd.cpy()
+		tmp.put(ai.color, m.loc)
+		v := ai.alphaBeta(tmp, ai.depth-1, alpha, MAXINT, false).value
+		if ai.clock.aborted {
+			break
+		}
+		if v <= alpha {
+			continue
+		}
+		ai.pv[0] = append(append(ai.pv[0][:0], m.loc), ai.pv[3]...)
+		ai.reachedDepth = min(ai.depth, empties)
+		i := len(values)
+		for i > 0 && values[i-1] < v {
+			i--
+		}
+		values = append(values[:i], append([]int{v}, values[i:]...)...)
+		results = append(results[:i], append([]Result{ai.result(node{m.loc, v})}, results[i:]...)...)
+		if n > 0 && len(values) > n {
+			values, results = values[:n], results[:n]
+		}
+	}
+	ai.nodesPool.freeOne()
+
+	if len(results) == 0 {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("builtin ai: %v", ctx.Err())
+		}
+		return nil, fmt.Errorf("builtin ai %v: no valid move", ai.color)
 	}
+	return results, nil
+}
+
+// Close stops the running search, which returns as SearchContext does when its
+// context is done, and makes any later search stop as soon as it starts
+func (ai *AI8) Close() {
+	ai.clock.close()
 }
 
 func (ai *AI8) setPhase(bd bboard8) {
@@ -114,12 +275,85 @@ func (ai *AI8) setDepth() {
 	}
 }
 
+// SetTableSize sets the memory of the transposition table in megabytes,
+// 0 turns it off. The table is emptied.
+func (ai *AI8) SetTableSize(mb int) {
+	ai.tableMB = mb
+	ai.sizeTables()
+}
+
+// SetThreads sets the goroutines a search runs on, 0 for one per CPU. With more
+// than one the moves of the root are searched in parallel, every goroutine with
+// a transposition table of its own, the tables together taking the memory of
+// SetTableSize; changing the count empties them. A search on one goroutine is
+// deterministic, on more the nodes and the line vary, and so may the move of a
+// search that is not exact as the tables differ. Analyze always runs on one.
+func (ai *AI8) SetThreads(threads int) {
+	threads = threadCount(threads)
+	if threads-1 == len(ai.helpers) {
+		return
+	}
+	for len(ai.helpers) < threads-1 {
+		h := NewAI8(board.BLACK, Level(ai.level))
+		ai.helpers = append(ai.helpers, h)
+	}
+	ai.helpers = ai.helpers[:threads-1]
+	ai.sizeTables()
+}
+
+// tableShare is the megabytes of the table of every goroutine of a search, at
+// least one unless tables are off
+func (ai *AI8) tableShare() int {
+	if ai.tableMB <= 0 {
+		return 0
+	}
+	return max(ai.tableMB/(len(ai.helpers)+1), 1)
+}
+
+// sizeTables empties the table and gives every helper its share
+func (ai *AI8) sizeTables() {
+	ai.table = nil
+	mb := ai.tableShare()
+	for _, h := range ai.helpers {
+		h.SetTableSize(mb)
+	}
+}
+
+// SetInfo sets who is told what a search found, after every ply of a search
+// with a time budget and once at the end of one without. f is called by the
+// goroutine of the search, nil tells no one and is the default.
+func (ai *AI8) SetInfo(f func(Result)) {
+	ai.info = f
+}
+
+// SetRules sets the rule set the AI plays to win under
+func (ai *AI8) SetRules(r board.Rules) {
+	ai.rules = r
+}
+
+// SetMoveTime gives every search d instead of the depth of the level, 0 goes
+// back to the level
+func (ai *AI8) SetMoveTime(d time.Duration) {
+	ai.clock.moveTime = d
+}
+
+// SetGameTime gives the rest of the game d, shared by the moves left, 0 for
+// no game budget. A search never takes more than SetMoveTime either.
+func (ai *AI8) SetGameTime(d time.Duration) {
+	ai.clock.gameLeft = d
+}
+
 func (ai *AI8) heuristic(bd bboard8) int {
+	var v int
 	if ai.phase == 1 { // phase 1
-		return bd.eval(ai.color)
+		v = bd.eval(ai.color)
 	} else { // phase 2
-		return bd.count(ai.color) - bd.count(ai.opponent)
+		v = bd.count(ai.color) - bd.count(ai.opponent)
+	}
+	if ai.rules == board.Anti {
+		v = -v
 	}
+	return v
 }
 
 func (ai *AI8) sortedValidNodes(bd bboard8, cl color) (all nodes) {
@@ -130,7 +364,11 @@ func (ai *AI8) sortedValidNodes(bd bboard8, cl color) (all nodes) {
 			if (u1<<loc)&allValid != 0 {
 				tmp := bd.cpy()
 				tmp.put(cl, loc)
-				all = append(all, node{loc, tmp.eval(cl)})
+				v := tmp.eval(cl)
+				if ai.rules == board.Anti {
+					v = -v
+				}
+				all = append(all, node{loc, v})
 			}
 		}
 		all.sortDesc()
@@ -151,12 +389,161 @@ func (ai *AI8) sortedValidNodes(bd bboard8, cl color) (all nodes) {
 	return
 }
 
+// deepen searches one ply deeper at a time until half the budget is spent, as
+// the next search would not finish in the other half, and returns the best move
+// of the deepest search that finished. The time never stops the first search,
+// and one to the end of the game is the last.
+func (ai *AI8) deepen(bd bboard8, budget time.Duration) (node, Result) {
+	empties := bd.emptyCount()
+	best := node{-1, 0}
+	var bestRes Result
+	bestPhase, bestDepth := 1, 0
+	for depth := 1; ; depth++ {
+		ai.phase, ai.depth = 1, depth
+		if depth >= empties {
+			ai.phase, ai.depth = 2, MAXINT
+		}
+		ai.table.prepare(ai.color, ai.rules)
+		res := ai.alphaBetaHelper(bd, ai.depth)
+		if ai.clock.aborted {
+			break
+		}
+		best, bestPhase, bestDepth = res, ai.phase, min(depth, empties)
+		ai.reachedDepth = bestDepth
+		bestRes = ai.result(best)
+		ai.report(bestRes)
+		if depth == 1 {
+			ai.clock.deadline = ai.started.Add(budget)
+		}
+		if ai.phase == 2 || time.Since(ai.started) > budget/2 {
+			break
+		}
+	}
+	ai.clock.deadline = time.Time{}
+	ai.phase, ai.reachedDepth = bestPhase, bestDepth
+	return best, bestRes
+}
+
 func (ai *AI8) alphaBetaHelper(bd bboard8, depth int) node {
+	if len(ai.helpers) > 0 {
+		return ai.parallelRoot(bd, depth)
+	}
 	return ai.alphaBeta(bd, depth, MININT, MAXINT, true)
 }
 
+// parallelRoot is the root of alphaBeta with its moves shared by the helpers.
+// The first move is searched alone to get a bound, the others then only need
+// to prove they are not better. A move as good as the best is searched exactly
+// too, so the first of the best moves is picked as alphaBeta does.
+func (ai *AI8) parallelRoot(bd bboard8, depth int) node {
+	side, ttDepth := ai.color, depth
+	if ai.phase == 2 {
+		ttDepth = MAXINT
+	}
+	key := bd.key(side)
+	e, hit := ai.table.probe(key, ai.phase)
+	moves := ai.sortedValidNodes(bd, ai.color)
+	defer ai.nodesPool.freeOne()
+	if len(moves) < 2 || bd.isOver() || hit && int(e.depth) >= ttDepth && e.bound == boundExact {
+		return ai.alphaBeta(bd, depth, MININT, MAXINT, true)
+	}
+	if hit {
+		moves.first(int(e.move))
+	}
+	ai.nodes++
+	ai.pv[0] = ai.pv[0][:0]
+
+	values := make([]int, len(moves))
+	pvs := make([][]int, len(moves))
+	tmp := bd.cpy()
+	tmp.put(ai.color, moves[0].loc)
+	values[0] = ai.alphaBeta(tmp, depth-1, MININT, MAXINT, false).value
+	if ai.clock.aborted {
+		return node{-1, 0}
+	}
+	pvs[0] = append([]int(nil), ai.pv[3]...)
+
+	bound, next := int64(values[0]), int64(0)
+	done := make([]bool, len(moves))
+	done[0] = true
+	work := func(w *AI8) {
+		for {
+			i := int(atomic.AddInt64(&next, 1))
+			if i >= len(moves) || w.clock.aborted {
+				return
+			}
+			tmp := bd.cpy()
+			tmp.put(ai.color, moves[i].loc)
+			alpha := int(atomic.LoadInt64(&bound)) - 1
+			v := w.alphaBeta(tmp, depth-1, alpha, MAXINT, false).value
+			if w.clock.aborted {
+				return
+			}
+			values[i], done[i] = v, true
+			if v > alpha {
+				pvs[i] = append([]int(nil), w.pv[3]...)
+				raise(&bound, v)
+			}
+		}
+	}
+	var wg sync.WaitGroup
+	for _, h := range ai.helpers {
+		h.follow(ai)
+		wg.Add(1)
+		go func(h *AI8) {
+			defer wg.Done()
+			work(h)
+		}(h)
+	}
+	work(ai)
+	wg.Wait()
+
+	for _, h := range ai.helpers {
+		ai.nodes += h.nodes
+		ai.selDepth = max(ai.selDepth, h.selDepth)
+		if h.clock.aborted {
+			ai.clock.aborted = true
+		}
+	}
+	best := 0
+	for i := range moves {
+		if done[i] && values[i] > values[best] {
+			best = i
+		}
+	}
+	ai.pv[0] = append(append(ai.pv[0], moves[best].loc), pvs[best]...)
+	if !ai.clock.aborted {
+		ai.table.store(key, ai.phase, ttDepth, values[best], boundExact, moves[best].loc)
+	}
+	return node{moves[best].loc, values[best]}
+}
+
+// follow makes a helper search the same tree as ai
+func (h *AI8) follow(ai *AI8) {
+	h.color, h.opponent, h.rules = ai.color, ai.opponent, ai.rules
+	h.phase, h.depth = ai.phase, ai.depth
+	h.nodes, h.selDepth = 0, 0
+	h.clock.deadline, h.clock.done, h.clock.aborted = ai.clock.deadline, ai.clock.done, false
+	if h.table == nil && h.tableMB > 0 {
+		h.table = newTable(h.tableMB)
+	}
+	h.table.prepare(h.color, h.rules)
+}
+
 func (ai *AI8) alphaBeta(bd bboard8, depth int, alpha int, beta int, maxLayer bool) node {
 	ai.nodes++
+	if ai.clock.expired(ai.nodes) {
+		return node{-1, 0}
+	}
+	ply := ai.depth - depth
+	ai.selDepth = max(ai.selDepth, ply)
+	pv, child := 2*ply, 2*ply+2
+	if maxLayer {
+		child++
+	} else {
+		pv++
+	}
+	ai.pv[pv] = ai.pv[pv][:0]
 
 	if depth == 0 {
 		ai.reachedDepth = ai.depth
@@ -169,6 +556,39 @@ func (ai *AI8) alphaBeta(bd bboard8, depth int, alpha int, beta int, maxLayer bo
 		return node{-1, v}
 	}
 
+	// the table is keyed by the side to move too, and a search to the end is
+	// as deep as any other
+	side, ttDepth := ai.color, depth
+	if !maxLayer {
+		side = ai.opponent
+	}
+	if ai.phase == 2 {
+		ttDepth = MAXINT
+	}
+	key := bd.key(side)
+	alphaOrig, betaOrig := alpha, beta
+	ttMove := -1
+	if e, ok := ai.table.probe(key, ai.phase); ok {
+		ttMove = int(e.move)
+		if int(e.depth) >= ttDepth {
+			v := int(e.value)
+			switch e.bound {
+			case boundExact:
+				if ttMove >= 0 {
+					ai.pv[pv] = append(ai.pv[pv], ttMove)
+				}
+				return node{ttMove, v}
+			case boundLower:
+				alpha = max(alpha, v)
+			case boundUpper:
+				beta = min(beta, v)
+			}
+			if beta <= alpha {
+				return node{ttMove, v}
+			}
+		}
+	}
+
 	if maxLayer {
 		maxValue := MININT
 		bestNode := node{-1, maxValue}
@@ -176,17 +596,28 @@ func (ai *AI8) alphaBeta(bd bboard8, depth int, alpha int, beta int, maxLayer bo
 		aiValid := ai.sortedValidNodes(bd, ai.color)
 		if len(aiValid) == 0 { // 沒地方下，換邊
 			ai.nodesPool.freeOne()
-			return ai.alphaBeta(bd, depth, alpha, beta, false)
+			res := ai.alphaBeta(bd, depth, alpha, beta, false)
+			ai.pv[pv] = append(append(ai.pv[pv], -1), ai.pv[pv+1]...)
+			return res
 		}
+		aiValid.first(ttMove)
 
 		for i := range aiValid {
 			tmp := bd.cpy()
 			tmp.put(ai.color, aiValid[i].loc)
 			eval := ai.alphaBeta(tmp, depth-1, alpha, beta, false).value
+			if ai.clock.aborted {
+				ai.nodesPool.freeOne()
+				if depth == ai.depth && bestNode.loc >= 0 { // the root keeps the moves it finished
+					return node{bestNode.loc, maxValue}
+				}
+				return node{-1, 0}
+			}
 
 			if eval > maxValue {
 				maxValue = eval
 				bestNode = aiValid[i]
+				ai.pv[pv] = append(append(ai.pv[pv][:0], bestNode.loc), ai.pv[child]...)
 			}
 			alpha = max(alpha, maxValue)
 			if beta <= alpha {
@@ -195,6 +626,7 @@ func (ai *AI8) alphaBeta(bd bboard8, depth int, alpha int, beta int, maxLayer bo
 		}
 
 		ai.nodesPool.freeOne()
+		ai.table.storeResult(key, ai.phase, ttDepth, maxValue, alphaOrig, betaOrig, bestNode.loc)
 		return node{bestNode.loc, maxValue}
 	} else {
 		minValue := MAXINT
@@ -203,17 +635,25 @@ func (ai *AI8) alphaBeta(bd bboard8, depth int, alpha int, beta int, maxLayer bo
 		opValid := ai.sortedValidNodes(bd, ai.opponent)
 		if len(opValid) == 0 { // 對手沒地方下，換邊
 			ai.nodesPool.freeOne()
-			return ai.alphaBeta(bd, depth, alpha, beta, true)
+			res := ai.alphaBeta(bd, depth, alpha, beta, true)
+			ai.pv[pv] = append(append(ai.pv[pv], -1), ai.pv[pv-1]...)
+			return res
 		}
+		opValid.first(ttMove)
 
 		for i := range opValid {
 			tmp := bd.cpy()
 			tmp.put(ai.opponent, opValid[i].loc)
 			eval := ai.alphaBeta(tmp, depth-1, alpha, beta, true).value
+			if ai.clock.aborted {
+				ai.nodesPool.freeOne()
+				return node{-1, 0}
+			}
 
 			if eval < minValue {
 				minValue = eval
 				bestNode = opValid[i]
+				ai.pv[pv] = append(append(ai.pv[pv][:0], bestNode.loc), ai.pv[child]...)
 			}
 
 			beta = min(beta, minValue)
@@ -223,6 +663,7 @@ func (ai *AI8) alphaBeta(bd bboard8, depth int, alpha int, beta int, maxLayer bo
 		}
 
 		ai.nodesPool.freeOne()
+		ai.table.storeResult(key, ai.phase, ttDepth, minValue, alphaOrig, betaOrig, bestNode.loc)
 		return node{bestNode.loc, minValue}
 	}
 }
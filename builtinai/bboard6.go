@@ -1,22 +1,62 @@
 package builtinai
 
+import (
+	"fmt"
+	"othello/board"
+)
+
 type bboard6 struct {
 	black, white uint64
+
+	// zobrist key of the discs, kept up to date by assign, clear and flip
+	hash uint64
 }
 
-func newBboard6(input string) bboard6 {
+// parseBboard6 reads a board string in the format of board.ParseBoard
+func parseBboard6(input string) (bboard6, error) {
+	if len(input) != 36 {
+		return bboard6{}, fmt.Errorf("invalid board length %d, want 36 for 6x6", len(input))
+	}
 	bd := bboard6{}
-	for i := 0; i < 36; i++ {
-		switch input[i] {
-		case 'X':
-			bd.assign(BLACK, i)
-		case 'O':
-			bd.assign(WHITE, i)
-		case '+':
+	for loc := 0; loc < 36; loc++ {
+		cl, err := board.ColorFromChar(input[loc])
+		if err != nil {
+			return bboard6{}, fmt.Errorf("%v at offset %d", err, loc)
+		}
+		if cl == board.BORDER {
+			return bboard6{}, fmt.Errorf("blocked square at offset %d is not supported", loc)
+		}
+		if cl != board.NONE {
+			bd.assign(color(cl), loc)
+		}
+	}
+	return bd, nil
+}
+
+// bboard6FromBoard reads the discs of bd, a 6x6 board of black and white only
+func bboard6FromBoard(bd *board.Board) (bboard6, error) {
+	if bd.Width() != SIZE6 || bd.Height() != SIZE6 {
+		return bboard6{}, fmt.Errorf("invalid board size %dx%d, want 6x6", bd.Width(), bd.Height())
+	}
+	res := bboard6{}
+	for loc := 0; loc < SIZE6*SIZE6; loc++ {
+		switch cl := bd.AtXY(loc%SIZE6, loc/SIZE6); cl {
+		case board.NONE:
+		case board.BLACK, board.WHITE:
+			res.assign(color(cl), loc)
 		default:
-			panic("input err: " + string(input[i]))
+			return bboard6{}, fmt.Errorf("%v square at offset %d is not supported", cl, loc)
 		}
 	}
+	return res, nil
+}
+
+// newBboard6 is parseBboard6 for strings known to be valid, it panics on errors
+func newBboard6(input string) bboard6 {
+	bd, err := parseBboard6(input)
+	if err != nil {
+		panic(err)
+	}
 	return bd
 }
 
@@ -57,7 +97,7 @@ func (bd bboard6) visualize() (res string) {
 }
 
 func (bd bboard6) cpy() bboard6 {
-	return bboard6{bd.black, bd.white}
+	return bboard6{bd.black, bd.white, bd.hash}
 }
 
 func (bd bboard6) at(loc int) color {
@@ -77,6 +117,7 @@ func (bd *bboard6) assign(cl color, loc int) {
 	} else {
 		bd.white |= sh
 	}
+	bd.hash ^= zobrist6[zobristIndex(cl)][loc]
 }
 
 func (bd *bboard6) put(cl color, loc int) {
@@ -97,6 +138,9 @@ func (bd *bboard6) putAndCheck(cl color, loc int) bool {
 }
 
 func (bd *bboard6) clear(loc int) {
+	if cl := bd.at(loc); cl != NONE {
+		bd.hash ^= zobrist6[zobristIndex(cl)][loc]
+	}
 	c := ^(u1 << loc)
 	bd.black &= c
 	bd.white &= c
@@ -264,6 +308,12 @@ func (bd *bboard6) flip(cl color, loc int) {
 		bd.white ^= captured_disks
 		bd.black ^= captured_disks
 	}
+	bd.hash ^= bitsKey6(captured_disks, BLACK) ^ bitsKey6(captured_disks, WHITE)
+}
+
+// key returns the zobrist key of the position with cl to move
+func (bd bboard6) key(cl color) uint64 {
+	return bd.hash ^ turnKey(cl)
 }
 
 func (bd bboard6) allValidLoc(cl color) uint64 {
@@ -1,26 +1,66 @@
 package builtinai
 
+import (
+	"fmt"
+	"othello/board"
+)
+
 const u1 uint64 = 1
 
 var DIR = []int{-8, -7, 1, 9, 8, 7, -1, -9}
 
 type bboard8 struct {
 	black, white uint64
+
+	// zobrist key of the discs, kept up to date by assign, clear and flip
+	hash uint64
 }
 
-func newBboard8(input string) bboard8 {
+// parseBboard8 reads a board string in the format of board.ParseBoard
+func parseBboard8(input string) (bboard8, error) {
+	if len(input) != 64 {
+		return bboard8{}, fmt.Errorf("invalid board length %d, want 64 for 8x8", len(input))
+	}
 	bd := bboard8{}
-	for i := 0; i < 64; i++ {
-		switch input[i] {
-		case 'X':
-			bd.assign(BLACK, i)
-		case 'O':
-			bd.assign(WHITE, i)
-		case '+':
+	for loc := 0; loc < 64; loc++ {
+		cl, err := board.ColorFromChar(input[loc])
+		if err != nil {
+			return bboard8{}, fmt.Errorf("%v at offset %d", err, loc)
+		}
+		if cl == board.BORDER {
+			return bboard8{}, fmt.Errorf("blocked square at offset %d is not supported", loc)
+		}
+		if cl != board.NONE {
+			bd.assign(color(cl), loc)
+		}
+	}
+	return bd, nil
+}
+
+// bboard8FromBoard reads the discs of bd, a 8x8 board of black and white only
+func bboard8FromBoard(bd *board.Board) (bboard8, error) {
+	if bd.Width() != SIZE8 || bd.Height() != SIZE8 {
+		return bboard8{}, fmt.Errorf("invalid board size %dx%d, want 8x8", bd.Width(), bd.Height())
+	}
+	res := bboard8{}
+	for loc := 0; loc < SIZE8*SIZE8; loc++ {
+		switch cl := bd.AtXY(loc%SIZE8, loc/SIZE8); cl {
+		case board.NONE:
+		case board.BLACK, board.WHITE:
+			res.assign(color(cl), loc)
 		default:
-			panic("input err: " + string(input[i]))
+			return bboard8{}, fmt.Errorf("%v square at offset %d is not supported", cl, loc)
 		}
 	}
+	return res, nil
+}
+
+// newBboard8 is parseBboard8 for strings known to be valid, it panics on errors
+func newBboard8(input string) bboard8 {
+	bd, err := parseBboard8(input)
+	if err != nil {
+		panic(err)
+	}
 	return bd
 }
 
@@ -61,7 +101,7 @@ func (bd bboard8) visualize() (res string) {
 }
 
 func (bd bboard8) cpy() bboard8 {
-	return bboard8{bd.black, bd.white}
+	return bboard8{bd.black, bd.white, bd.hash}
 }
 
 func (bd bboard8) at(loc int) color {
@@ -81,6 +121,7 @@ func (bd *bboard8) assign(cl color, loc int) {
 	} else {
 		bd.white |= sh
 	}
+	bd.hash ^= zobrist8[zobristIndex(cl)][loc]
 }
 
 func (bd *bboard8) put(cl color, loc int) {
@@ -101,6 +142,9 @@ func (bd *bboard8) putAndCheck(cl color, loc int) bool {
 }
 
 func (bd *bboard8) clear(loc int) {
+	if cl := bd.at(loc); cl != NONE {
+		bd.hash ^= zobrist8[zobristIndex(cl)][loc]
+	}
 	c := ^(u1 << loc)
 	bd.black &= c
 	bd.white &= c
@@ -314,6 +358,12 @@ func (bd *bboard8) flip(cl color, loc int) {
 		bd.white ^= captured_disks
 		bd.black ^= captured_disks
 	}
+	bd.hash ^= bitsKey8(captured_disks, BLACK) ^ bitsKey8(captured_disks, WHITE)
+}
+
+// key returns the zobrist key of the position with cl to move
+func (bd bboard8) key(cl color) uint64 {
+	return bd.hash ^ turnKey(cl)
 }
 
 func (bd bboard8) allValidLoc(cl color) uint64 {
@@ -0,0 +1,108 @@
+package builtinai
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// the least a move is given when the game budget is used up
+const MIN_MOVE_TIME = 10 * time.Millisecond
+
+// clock is the time budget of an AI and what else may stop its search. Without
+// a budget the depth of a search is set by the level, with one the AI deepens a
+// ply at a time until the time is up.
+type clock struct {
+	// the budget of every move and what is left of the budget of the game, 0 for none
+	moveTime time.Duration
+	gameLeft time.Duration
+
+	// when the running search has to stop, zero while it must not be stopped
+	deadline time.Time
+	aborted  bool
+
+	// closed when the caller of the running search gives up on it
+	done <-chan struct{}
+
+	// cancel closes done, close calls it from another goroutine
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	closed bool
+}
+
+// budget returns the time of the next move, or 0 if there is no budget. A game
+// budget is shared by the moves left, one every other empty square.
+func (c *clock) budget(empties int) time.Duration {
+	budget := c.moveTime
+	if c.gameLeft != 0 {
+		share := c.gameLeft / time.Duration(max((empties+1)/2, 1))
+		if share < MIN_MOVE_TIME {
+			share = MIN_MOVE_TIME
+		}
+		if budget == 0 || share < budget {
+			budget = share
+		}
+	}
+	return budget
+}
+
+// spend takes the time of a move from the game budget
+func (c *clock) spend(d time.Duration) {
+	if c.gameLeft == 0 {
+		return
+	}
+	c.gameLeft -= d
+	if c.gameLeft <= 0 {
+		c.gameLeft = time.Nanosecond // still a game budget, an empty one
+	}
+}
+
+// expired reports whether the search has to stop, the clock and done are read
+// every 1024 nodes
+func (c *clock) expired(nodes int) bool {
+	if c.aborted || nodes&1023 != 0 {
+		return c.aborted
+	}
+	if !c.deadline.IsZero() && time.Now().After(c.deadline) {
+		c.aborted = true
+	}
+	select {
+	case <-c.done:
+		c.aborted = true
+	default:
+	}
+	return c.aborted
+}
+
+// begin starts a search that stops when the returned context is done, that is
+// when ctx is or close is called
+func (c *clock) begin(ctx context.Context) context.Context {
+	ctx, cancel := context.WithCancel(ctx)
+	c.mu.Lock()
+	if c.closed {
+		cancel()
+	}
+	c.cancel = cancel
+	c.mu.Unlock()
+	c.done = ctx.Done()
+	return ctx
+}
+
+// end ends the search begin started
+func (c *clock) end() {
+	c.mu.Lock()
+	c.cancel()
+	c.cancel = nil
+	c.mu.Unlock()
+	c.done, c.aborted = nil, false
+}
+
+// close stops the running search, and any later one as soon as it starts
+func (c *clock) close() {
+	c.mu.Lock()
+	c.closed = true
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.mu.Unlock()
+}
@@ -0,0 +1,85 @@
+package builtinai
+
+import (
+	"context"
+	"othello/board"
+	"testing"
+	"time"
+)
+
+func TestMoveTime(t *testing.T) {
+	for _, size := range []int{SIZE6, SIZE8} {
+		pos := board.NewPosition(board.NewBoard(size), board.BLACK)
+		ai, err := NewEngine(size, LV_FIVE)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ai.SetMoveTime(200 * time.Millisecond)
+		start := time.Now()
+		res, err := ai.Search(pos)
+		spent := time.Since(start)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, _, ok := pos.Play(res.Move); !ok {
+			t.Errorf("%dx%d: %s is not valid", size, size, res.Move.Algebraic())
+		}
+		// deepening to the end would take far longer than the budget
+		if res.Exact || res.Depth < 2 || res.Depth >= pos.Board.EmptyCount() || spent > 5*time.Second {
+			t.Errorf("%dx%d: %+v in %v", size, size, res, spent)
+		}
+	}
+}
+
+func TestGameTime(t *testing.T) {
+	c := clock{moveTime: time.Second, gameLeft: 10 * time.Second}
+	if got := c.budget(40); got != 500*time.Millisecond {
+		t.Errorf("budget(40) = %v", got)
+	}
+	if got := c.budget(4); got != time.Second {
+		t.Errorf("budget(4) = %v", got)
+	}
+	c.spend(time.Minute)
+	if got := c.budget(4); got != MIN_MOVE_TIME {
+		t.Errorf("budget after the game time = %v", got)
+	}
+}
+
+func TestSearchContext(t *testing.T) {
+	pos := board.NewPosition(board.NewBoard(SIZE8), board.BLACK)
+	ai := NewAI8(board.BLACK, LV_FIVE)
+	ai.SetMoveTime(time.Hour)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	res, err := ai.SearchContext(ctx, pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := pos.Play(res.Move); !ok || ctx.Err() == nil || res.Exact || res.Depth >= pos.Board.EmptyCount() {
+		t.Errorf("%+v, context %v", res, ctx.Err())
+	}
+
+	// without a budget the root keeps the moves it finished, if any; solving
+	// this position takes seconds
+	end, err := board.ParsePosition("+OO++O++OOOOOO+X+OOOOOX+++OOXOXO+XOOXOXOXXXX+OXOO++X+OXO+++++OOO 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ai = NewAI8(board.BLACK, LV_FIVE)
+	time.AfterFunc(50*time.Millisecond, ai.Close)
+	start := time.Now()
+	res, err = ai.Search(end)
+	if err == nil {
+		if _, _, ok := end.Play(res.Move); !ok {
+			t.Errorf("%s is not valid", res.Move.Algebraic())
+		}
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Errorf("closed after %v", time.Since(start))
+	}
+	ai = NewAI8(board.BLACK, LV_FIVE)
+	ai.Close()
+	if _, err := ai.Search(pos); err == nil {
+		t.Error("a closed AI searched")
+	}
+}
@@ -0,0 +1,95 @@
+package builtinai
+
+import (
+	"context"
+	"fmt"
+	"othello/board"
+	"strings"
+	"time"
+)
+
+// Result is what a search found for the side to move
+type Result struct {
+	Move board.Point
+
+	// Score is how good Move is for the side to move under the rules of the AI,
+	// in discs: the final disc difference when Exact, otherwise the evaluation
+	// scaled to the number of squares
+	Score float64
+	Exact bool
+
+	// the plies searched, the deepest ply visited and the positions visited
+	Depth    int
+	SelDepth int
+	Nodes    int
+
+	// the moves expected from Move on, passes included
+	PV []board.Move
+
+	Elapsed time.Duration
+}
+
+// NPS is the nodes visited per second
+func (r Result) NPS() int {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return int(float64(r.Nodes) / r.Elapsed.Seconds())
+}
+
+// PrintInfo prints a line of what a search found, see SetInfo
+func PrintInfo(r Result) {
+	value := fmt.Sprintf("%+.2f", r.Score)
+	if r.Exact {
+		value = fmt.Sprintf("%+d", int(r.Score))
+	}
+	var pv []string
+	for _, m := range r.PV {
+		if m.Pass {
+			pv = append(pv, board.PassToken)
+		} else {
+			pv = append(pv, m.Point.Algebraic())
+		}
+	}
+	fmt.Printf("built-in AI: {depth: %d/%d, nodes: %d, nps: %d, time: %v, value: %s, pv: %s}\n",
+		r.Depth, r.SelDepth, r.Nodes, r.NPS(), r.Elapsed.Round(time.Millisecond), value, strings.Join(pv, " "))
+}
+
+// Engine is the built-in AI of one board size, see NewEngine
+type Engine interface {
+	Search(pos board.Position) (Result, error)
+	SearchContext(ctx context.Context, pos board.Position) (Result, error)
+	Move(pos board.Position) (board.Point, error)
+	Analyze(pos board.Position, depth, n int) ([]Result, error)
+	SetInfo(f func(Result))
+	SetRules(r board.Rules)
+	SetTableSize(mb int)
+	SetThreads(threads int)
+	SetMoveTime(d time.Duration)
+	SetGameTime(d time.Duration)
+	Close()
+}
+
+// NewEngine returns the built-in AI for boards of size x size
+func NewEngine(size int, lv Level) (Engine, error) {
+	switch size {
+	case SIZE6:
+		return NewAI6(board.BLACK, lv), nil
+	case SIZE8:
+		return NewAI8(board.BLACK, lv), nil
+	default:
+		return nil, fmt.Errorf("built-in AI does not support %dx%d", size, size)
+	}
+}
+
+// colorOf converts a side to move of the board package
+func colorOf(cl board.Color) (color, error) {
+	switch cl {
+	case board.BLACK:
+		return BLACK, nil
+	case board.WHITE:
+		return WHITE, nil
+	default:
+		return NONE, fmt.Errorf("%v can't move on a board of two colors", cl)
+	}
+}
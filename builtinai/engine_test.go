@@ -0,0 +1,110 @@
+package builtinai
+
+import (
+	"math/rand"
+	"othello/board"
+	"testing"
+	"time"
+)
+
+func TestPV(t *testing.T) {
+	for _, moves := range []string{"f5d6c3d3c4f4f6f3e6e7d7c5", "f5f6e6f4e3"} {
+		g, err := board.ParseTranscript(board.NewBoard(SIZE8), board.BLACK, moves)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pos := g.Position()
+
+		ai := NewAI8(board.BLACK, LV_THREE)
+		var infos []Result
+		ai.SetInfo(func(r Result) { infos = append(infos, r) })
+		ai.SetMoveTime(200 * time.Millisecond)
+		res, err := ai.Search(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(infos) < 2 || infos[len(infos)-1].Move != res.Move {
+			t.Fatalf("%s: %+v reported as %+v", pos, res, infos)
+		}
+		for i, info := range infos {
+			if info.Depth != i+1 || info.SelDepth < info.Depth || i > 0 && info.Nodes < infos[i-1].Nodes {
+				t.Errorf("%s: report %d is %+v", pos, i, info)
+			}
+		}
+		if len(res.PV) < res.Depth/2 || res.PV[0].Point != res.Move || res.PV[0].Color != pos.ToMove {
+			t.Errorf("%s: pv %v of %+v", pos, res.PV, res)
+		}
+		// every move of the line can be played
+		line := pos
+		for _, m := range res.PV {
+			ok := m.Color == line.ToMove
+			if m.Pass {
+				line, ok = line.Pass()
+			} else if ok {
+				line, _, ok = line.Play(m.Point)
+			}
+			if !ok {
+				t.Errorf("%s: %v of pv %v can't be played", pos, m, res.PV)
+				break
+			}
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	r := rand.New(rand.NewSource(7))
+	for n := 0; n < 5; n++ {
+		pos, ok := randomPosition(r, SIZE6, 12)
+		if !ok {
+			continue
+		}
+
+		ai := NewAI6(board.BLACK, LV_FIVE)
+		all, err := ai.Analyze(pos, 64, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != len(pos.ValidMoves()) {
+			t.Fatalf("%s: %d moves scored of %d", pos, len(all), len(pos.ValidMoves()))
+		}
+		// every score is the one a search finds after the move
+		for i, res := range all {
+			if !res.Exact || res.PV[0].Point != res.Move || i > 0 && res.Score > all[i-1].Score {
+				t.Errorf("%s: move %d is %+v", pos, i, res)
+			}
+			next, _, _ := pos.Play(res.Move)
+			want := 0.0
+			switch {
+			case next.IsOver():
+				black, white := next.Board.FinalScore(board.DiscsOnly)
+				want = float64(black - white)
+				if pos.ToMove == board.WHITE {
+					want = -want
+				}
+			case next.MustPass():
+				next, _ = next.Pass()
+				reply, _ := NewAI6(board.BLACK, LV_FIVE).Search(next)
+				want = reply.Score
+			default:
+				reply, _ := NewAI6(board.BLACK, LV_FIVE).Search(next)
+				want = -reply.Score
+			}
+			if res.Score != want {
+				t.Errorf("%s: %s scored %v, want %v", pos, res.Move.Algebraic(), res.Score, want)
+			}
+		}
+
+		top, err := ai.Analyze(pos, 64, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := range top {
+			if top[i].Score != all[i].Score {
+				t.Errorf("%s: top %d is %+v, want %+v", pos, i, top[i], all[i])
+			}
+		}
+		if len(top) != min(2, len(all)) {
+			t.Errorf("%s: %d of the top 2", pos, len(top))
+		}
+	}
+}
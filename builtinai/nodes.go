@@ -53,3 +53,15 @@ func (ns nodes) sortAsc() {
 		}
 	}
 }
+
+// first moves the node of loc to the front, the others keep their order
+func (ns nodes) first(loc int) {
+	for i := range ns {
+		if ns[i].loc == loc {
+			n := ns[i]
+			copy(ns[1:i+1], ns[:i])
+			ns[0] = n
+			return
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package builtinai
+
+import (
+	"runtime"
+	"sync/atomic"
+)
+
+// threadCount is the number of goroutines SetThreads(n) asks for
+func threadCount(n int) int {
+	if n <= 0 {
+		return runtime.NumCPU()
+	}
+	return n
+}
+
+// raise sets *p to v if v is larger, for the bound the threads of a search share
+func raise(p *int64, v int) {
+	for {
+		old := atomic.LoadInt64(p)
+		if int64(v) <= old || atomic.CompareAndSwapInt64(p, old, int64(v)) {
+			return
+		}
+	}
+}
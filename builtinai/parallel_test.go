@@ -0,0 +1,70 @@
+package builtinai
+
+import (
+	"math/rand"
+	"othello/board"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+func TestThreads(t *testing.T) {
+	r := rand.New(rand.NewSource(11))
+	for n := 0; n < 5; n++ {
+		pos, ok := randomPosition(r, SIZE8, 16)
+		if !ok {
+			continue
+		}
+
+		// an exact search finds the same move on one goroutine or four
+		one, four := NewAI8(board.BLACK, LV_FIVE), NewAI8(board.BLACK, LV_FIVE)
+		four.SetThreads(4)
+		want, err := one.Search(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := four.Search(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Exact || got.Move != want.Move || got.Score != want.Score || got.PV[0].Point != got.Move {
+			t.Errorf("%s: on four %+v, on one %+v", pos, got, want)
+		}
+	}
+
+	// the helpers stop with the search
+	ai := NewAI8(board.BLACK, LV_FIVE)
+	ai.SetThreads(4)
+	ai.SetMoveTime(100 * time.Millisecond)
+	pos := board.NewPosition(board.NewBoard(SIZE8), board.BLACK)
+	start := time.Now()
+	res, err := ai.Search(pos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := pos.Play(res.Move); !ok || res.Exact || res.Depth >= pos.Board.EmptyCount() || time.Since(start) > 5*time.Second {
+		t.Errorf("%+v in %v", res, time.Since(start))
+	}
+}
+
+func TestThreadTables(t *testing.T) {
+	ai := NewAI8(board.BLACK, LV_FIVE)
+	ai.SetTableSize(16)
+	ai.SetThreads(4)
+	if _, err := ai.Search(board.NewPosition(board.NewBoard(SIZE8), board.BLACK)); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tables of all the goroutines fit in the memory of SetTableSize
+	size := uintptr(len(ai.table.entries))
+	for _, h := range ai.helpers {
+		size += uintptr(len(h.table.entries))
+	}
+	if size*unsafe.Sizeof(entry{}) > 16<<20 || ai.tableShare() != 4 {
+		t.Errorf("%d entries on 4 goroutines", size)
+	}
+	ai.SetThreads(1)
+	if ai.table != nil || ai.tableShare() != 16 {
+		t.Error("one goroutine kept a share of the table")
+	}
+}
@@ -0,0 +1,75 @@
+package builtinai
+
+import (
+	"math/bits"
+	"othello/board"
+)
+
+// Perft counts the leaves of the game tree depth plies below bd with cl to move,
+// using the bitboards on 6x6 and 8x8 and board.Perft on the other boards.
+// It follows the same conventions as board.Perft.
+func Perft(bd *board.Board, cl board.Color, depth int) uint64 {
+	if !SupportBoard(bd) {
+		return board.Perft(bd, cl, depth)
+	}
+	aicl, err := colorOf(cl)
+	if err != nil {
+		return board.Perft(bd, cl, depth)
+	}
+	switch bd.Size() {
+	case SIZE6:
+		b6, _ := bboard6FromBoard(bd)
+		return perft6(b6, aicl, depth, false)
+	case SIZE8:
+		b8, _ := bboard8FromBoard(bd)
+		return perft8(b8, aicl, depth, false)
+	default:
+		return board.Perft(bd, cl, depth)
+	}
+}
+
+func perft6(bd bboard6, cl color, depth int, passed bool) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	valid := bd.allValidLoc(cl)
+	if valid == 0 {
+		if passed {
+			return 1 // neither side can move, the game ended here
+		}
+		return perft6(bd, cl.reverse(), depth-1, true)
+	}
+	if depth == 1 {
+		return uint64(hammingWeight(valid))
+	}
+	var count uint64
+	for ; valid != 0; valid &= valid - 1 {
+		tmp := bd.cpy()
+		tmp.put(cl, bits.TrailingZeros64(valid))
+		count += perft6(tmp, cl.reverse(), depth-1, false)
+	}
+	return count
+}
+
+func perft8(bd bboard8, cl color, depth int, passed bool) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	valid := bd.allValidLoc(cl)
+	if valid == 0 {
+		if passed {
+			return 1 // neither side can move, the game ended here
+		}
+		return perft8(bd, cl.reverse(), depth-1, true)
+	}
+	if depth == 1 {
+		return uint64(hammingWeight(valid))
+	}
+	var count uint64
+	for ; valid != 0; valid &= valid - 1 {
+		tmp := bd.cpy()
+		tmp.put(cl, bits.TrailingZeros64(valid))
+		count += perft8(tmp, cl.reverse(), depth-1, false)
+	}
+	return count
+}
@@ -0,0 +1,93 @@
+package builtinai
+
+import (
+	"flag"
+	"math/bits"
+	"math/rand"
+	"othello/board"
+	"testing"
+)
+
+var (
+	crossGames = flag.Int("crossgames", 300, "random games walked by TestCrossCheck")
+	crossSeed  = flag.Int64("crossseed", 1, "seed of the random games of TestCrossCheck")
+)
+
+func TestPerft(t *testing.T) {
+	for _, size := range []int{SIZE6, SIZE8} {
+		for d, want := range board.PerftCounts[size] {
+			if want > 5000000 {
+				break
+			}
+			if got := Perft(board.NewBoard(size), board.BLACK, d+1); got != want {
+				t.Errorf("%dx%d depth %d: got %d, want %d", size, size, d+1, got, want)
+			}
+		}
+	}
+}
+
+// the legal moves of both sides as a bitboard
+func validBits(bd *board.Board, cl board.Color) (res uint64) {
+	for _, p := range bd.AllValidPoint(cl) {
+		res |= u1 << (p.Y*bd.Size() + p.X)
+	}
+	return
+}
+
+// walk random games and check board.Board against the bitboards after every ply,
+// run with -crossgames=N for a longer walk and -crossseed=N for other games
+func TestCrossCheck(t *testing.T) {
+	seed := *crossSeed
+	r := rand.New(rand.NewSource(seed))
+	for game := 0; game < *crossGames; game++ {
+		size := SIZE8
+		if game%2 == 1 {
+			size = SIZE6
+		}
+		bd := board.NewBoard(size)
+		var b6 bboard6
+		var b8 bboard8
+		if size == SIZE6 {
+			b6 = newBboard6(bd.String())
+		} else {
+			b8 = newBboard8(bd.String())
+		}
+		cl := board.BLACK
+
+		for ply := 0; !bd.IsOver(); ply++ {
+			var valid uint64
+			over := false
+			if size == SIZE6 {
+				valid, over = b6.allValidLoc(color(cl)), b6.isOver()
+			} else {
+				valid, over = b8.allValidLoc(color(cl)), b8.isOver()
+			}
+			if want := validBits(bd, cl); valid != want || over {
+				t.Fatalf("seed %d, ply %d, %v to move: legal moves %x, want %x\n%s", seed, ply, cl, valid, want, bd.Visualize())
+			}
+
+			if valid != 0 {
+				moves := bits.OnesCount64(valid)
+				for i := r.Intn(moves); i > 0; i-- {
+					valid &= valid - 1
+				}
+				loc := bits.TrailingZeros64(valid)
+				bd.PutWithoutCheck(cl, board.NewPoint(loc%size, loc/size))
+				if size == SIZE6 {
+					b6.put(color(cl), loc)
+				} else {
+					b8.put(color(cl), loc)
+				}
+			}
+			cl = cl.Opponent()
+
+			got := b8.String()
+			if size == SIZE6 {
+				got = b6.String()
+			}
+			if got != bd.String() {
+				t.Fatalf("seed %d, ply %d: boards differ\n%s\n%s", seed, ply, bd.String(), got)
+			}
+		}
+	}
+}
@@ -24,6 +24,9 @@ func newPool(cap int) pool {
 
 // clear the slice to avoid data confusion
 func (p *pool) getClearOne() nodes {
+	if p.curr == len(p.stack) { // deeper than the pool was made for
+		p.stack = append(p.stack, make(nodes, 0, INIT_SIZE))
+	}
 	ns := p.stack[p.curr]
 	p.curr++
 	return ns[:0]
@@ -0,0 +1,122 @@
+package builtinai
+
+import (
+	"fmt"
+	"othello/board"
+	"time"
+)
+
+// RolitAI plays Rolit on any board with a max-n search: every player picks the
+// move best for itself, assuming the others do the same
+type RolitAI struct {
+	players int
+
+	// the larger the stronger, level is between 0~4
+	level int
+
+	// traversed nodes count
+	nodes int
+
+	info func(Result)
+}
+
+func NewRolitAI(players int, lv Level) *RolitAI {
+	return &RolitAI{players: players, level: int(lv)}
+}
+
+// Move returns the best move for the side to move of pos
+func (ai *RolitAI) Move(pos board.Position) (board.Point, error) {
+	turn := pos.ToMove.Number() - 1
+	if turn < 0 || turn >= ai.players {
+		return board.Point{X: -1, Y: -1}, fmt.Errorf("builtin ai: %v is not a player of %d", pos.ToMove, ai.players)
+	}
+	bd := pos.Board.Copy()
+	if len(bd.RolitMoves(pos.ToMove)) == 0 {
+		return board.Point{X: -1, Y: -1}, fmt.Errorf("builtin ai: %v has no move", pos.ToMove)
+	}
+	ai.nodes = 0
+	start := time.Now()
+	best, values := ai.maxN(bd, turn, ai.level+1)
+	if ai.info != nil {
+		ai.info(Result{
+			Move:     best,
+			Score:    float64(values[turn]),
+			Depth:    ai.level + 1,
+			SelDepth: ai.level + 1,
+			Nodes:    ai.nodes,
+			PV:       []board.Move{board.NewMove(pos.ToMove, best)},
+			Elapsed:  time.Since(start),
+		})
+	}
+	return best, nil
+}
+
+// SetInfo sets who is told what a search found, once a move, Score being the
+// value of Move for the side to move. nil tells no one and is the default.
+func (ai *RolitAI) SetInfo(f func(Result)) {
+	ai.info = f
+}
+
+func (ai *RolitAI) Close() {}
+
+// maxN returns the move of the player turn and the value it leads to for everyone
+func (ai *RolitAI) maxN(bd *board.Board, turn, depth int) (board.Point, []int) {
+	ai.nodes++
+	cl := board.Seats[turn]
+	moves := bd.RolitMoves(cl)
+	if len(moves) == 0 {
+		return board.Point{X: -1, Y: -1}, ai.final(bd)
+	}
+	if depth == 0 {
+		return board.Point{X: -1, Y: -1}, ai.heuristic(bd)
+	}
+
+	var best board.Point
+	var bestValues []int
+	for _, p := range moves {
+		pl, _ := bd.PlayRolit(cl, p)
+		_, values := ai.maxN(bd, (turn+1)%ai.players, depth-1)
+		bd.Unplay(pl)
+		if bestValues == nil || values[turn] > bestValues[turn] {
+			best, bestValues = p, values
+		}
+	}
+	return best, bestValues
+}
+
+// heuristic counts the discs of every player, corners are worth more as they
+// can't be flipped, and the squares next to them less
+func (ai *RolitAI) heuristic(bd *board.Board) []int {
+	values := make([]int, ai.players)
+	w, h := bd.Width(), bd.Height()
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			turn := bd.AtXY(x, y).Number() - 1
+			if turn < 0 || turn >= ai.players {
+				continue
+			}
+			onEdgeX, onEdgeY := x == 0 || x == w-1, y == 0 || y == h-1
+			nearEdgeX, nearEdgeY := x == 1 || x == w-2, y == 1 || y == h-2
+			switch {
+			case onEdgeX && onEdgeY:
+				values[turn] += 8
+			case (onEdgeX || nearEdgeX) && (onEdgeY || nearEdgeY):
+				values[turn] += 0
+			case onEdgeX || onEdgeY:
+				values[turn] += 2
+			default:
+				values[turn] += 1
+			}
+		}
+	}
+	return values
+}
+
+// final values the end of the game by the discs, far beyond any heuristic
+func (ai *RolitAI) final(bd *board.Board) []int {
+	values := make([]int, ai.players)
+	for i := range values {
+		values[i] = bd.CountPieces(board.Seats[i]) * 1000
+	}
+	return values
+}
@@ -0,0 +1,30 @@
+package builtinai
+
+import (
+	"othello/board"
+	"testing"
+)
+
+func TestRolitAI(t *testing.T) {
+	for players := 3; players <= 4; players++ {
+		g := board.NewRolit(board.NewRolitBoard(8, players), players)
+		ai := NewRolitAI(players, LV_THREE)
+		var info Result
+		ai.SetInfo(func(r Result) { info = r })
+		for !g.IsOver() {
+			p, err := ai.Move(g.Position())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Move != p || info.Depth != int(LV_THREE)+1 || info.Nodes == 0 {
+				t.Errorf("%v moved %v but reported %+v", g.Turn(), p, info)
+			}
+			if !g.Play(p) {
+				t.Fatal(g.Turn(), "played", p, "\n", g.Board().Visualize())
+			}
+		}
+		if _, err := ai.Move(g.Position()); err == nil {
+			t.Error("moved after the end")
+		}
+	}
+}
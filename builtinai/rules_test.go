@@ -0,0 +1,81 @@
+package builtinai
+
+import (
+	"math/rand"
+	"othello/board"
+	"testing"
+)
+
+// exact final disc difference for cl with both sides playing to maximize their own
+// difference, or minimize it under board.Anti
+func solve(pos board.Position, cl board.Color, rules board.Rules) int {
+	if pos.IsOver() {
+		diff := pos.Board.CountPieces(cl) - pos.Board.CountPieces(cl.Opponent())
+		if rules == board.Anti {
+			return -diff
+		}
+		return diff
+	}
+	if pos.MustPass() {
+		next, _ := pos.Pass()
+		return -solve(next, cl.Opponent(), rules)
+	}
+	best := -1000
+	for _, p := range pos.ValidMoves() {
+		next, _, _ := pos.Play(p)
+		best = max(best, -solve(next, cl.Opponent(), rules))
+	}
+	return best
+}
+
+// randomPosition plays random moves from the start of a size board down to
+// empties empty squares, ok is false if the game ended or the side to move has
+// to pass there
+func randomPosition(r *rand.Rand, size, empties int) (pos board.Position, ok bool) {
+	pos = board.NewPosition(board.NewBoard(size), board.BLACK)
+	for !pos.IsOver() && pos.Board.EmptyCount() > empties {
+		if pos.MustPass() {
+			pos, _ = pos.Pass()
+			continue
+		}
+		ps := pos.ValidMoves()
+		pos, _, _ = pos.Play(ps[r.Intn(len(ps))])
+	}
+	return pos, !pos.IsOver() && !pos.MustPass()
+}
+
+func TestRules(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	for _, size := range []int{SIZE6, SIZE8} {
+		for n := 0; n < 10; n++ {
+			// random play down to a few empty squares, where the AI searches to the end
+			pos, ok := randomPosition(r, size, 9)
+			if !ok {
+				continue
+			}
+
+			for _, rules := range board.AllRules {
+				ai, err := NewEngine(size, LV_FIVE)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ai.SetRules(rules)
+				res, err := ai.Search(pos)
+				if err != nil {
+					t.Fatal(err)
+				}
+				p := res.Move
+				if want := float64(solve(pos, pos.ToMove, rules)); !res.Exact || res.Score != want {
+					t.Errorf("%v rules, %s: score %v exact %v, want %v", rules, pos, res.Score, res.Exact, want)
+				}
+				next, _, ok := pos.Play(p)
+				if !ok {
+					t.Fatal("invalid move", p)
+				}
+				if got, want := -solve(next, pos.ToMove.Opponent(), rules), solve(pos, pos.ToMove, rules); got != want {
+					t.Errorf("%v rules, %s: %s scores %d, best is %d", rules, pos, p.Algebraic(), got, want)
+				}
+			}
+		}
+	}
+}
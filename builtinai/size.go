@@ -0,0 +1,14 @@
+package builtinai
+
+import "othello/board"
+
+// SupportSize reports whether the built-in AI can play on a board of size x size
+func SupportSize(size int) bool {
+	return size == SIZE6 || size == SIZE8
+}
+
+// SupportBoard reports whether the built-in AI can play on bd, a square board
+// of a supported size without blocked squares or the colors of Rolit
+func SupportBoard(bd *board.Board) bool {
+	return !bd.Irregular() && SupportSize(bd.Size()) && bd.CountPieces(board.RED)+bd.CountPieces(board.BLUE) == 0
+}
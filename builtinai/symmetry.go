@@ -0,0 +1,112 @@
+package builtinai
+
+import (
+	"math/bits"
+	"othello/board"
+)
+
+// where each square goes under every transform, 6x6 has no cheap bit tricks
+var transform6 [8][36]int
+
+func init() {
+	for _, t := range board.Transforms {
+		for loc := 0; loc < 36; loc++ {
+			p := board.NewPoint(loc%SIZE6, loc/SIZE6).Transform(t, SIZE6)
+			transform6[t][loc] = p.Y*SIZE6 + p.X
+		}
+	}
+}
+
+func transformBits6(x uint64, t board.Transform) (res uint64) {
+	for ; x != 0; x &= x - 1 {
+		res |= u1 << transform6[t][bits.TrailingZeros64(x)]
+	}
+	return
+}
+
+func (bd bboard6) transform(t board.Transform) bboard6 {
+	black, white := transformBits6(bd.black, t), transformBits6(bd.white, t)
+	return bboard6{black, white, bitsKey6(black, BLACK) ^ bitsKey6(white, WHITE)}
+}
+
+// canonical follows the same rule as board.Board.Canonical
+func (bd bboard6) canonical() (bboard6, board.Transform) {
+	best, bestT := bd, board.Identity
+	for _, t := range board.Transforms[1:] {
+		if nbd := bd.transform(t); nbd.hash < best.hash {
+			best, bestT = nbd, t
+		}
+	}
+	return best, bestT
+}
+
+// bit tricks from https://www.chessprogramming.org/Flipping_Mirroring_and_Rotating
+// row y is byte y and column x is bit x of the byte
+
+func flipVertical8(x uint64) uint64 {
+	return bits.ReverseBytes64(x)
+}
+
+func flipHorizontal8(x uint64) uint64 {
+	const (
+		k1 = 0x5555555555555555
+		k2 = 0x3333333333333333
+		k4 = 0x0f0f0f0f0f0f0f0f
+	)
+	x = ((x >> 1) & k1) | ((x & k1) << 1)
+	x = ((x >> 2) & k2) | ((x & k2) << 2)
+	x = ((x >> 4) & k4) | ((x & k4) << 4)
+	return x
+}
+
+func transpose8(x uint64) uint64 {
+	const (
+		k1 = 0x5500550055005500
+		k2 = 0x3333000033330000
+		k4 = 0x0f0f0f0f00000000
+	)
+	t := k4 & (x ^ (x << 28))
+	x ^= t ^ (t >> 28)
+	t = k2 & (x ^ (x << 14))
+	x ^= t ^ (t >> 14)
+	t = k1 & (x ^ (x << 7))
+	x ^= t ^ (t >> 7)
+	return x
+}
+
+func transformBits8(x uint64, t board.Transform) uint64 {
+	switch t {
+	case board.Rotate90:
+		return flipHorizontal8(transpose8(x))
+	case board.Rotate180:
+		return flipHorizontal8(flipVertical8(x))
+	case board.Rotate270:
+		return transpose8(flipHorizontal8(x))
+	case board.FlipHorizontal:
+		return flipHorizontal8(x)
+	case board.FlipVertical:
+		return flipVertical8(x)
+	case board.Transpose:
+		return transpose8(x)
+	case board.AntiTranspose:
+		return flipVertical8(flipHorizontal8(transpose8(x)))
+	default:
+		return x
+	}
+}
+
+func (bd bboard8) transform(t board.Transform) bboard8 {
+	black, white := transformBits8(bd.black, t), transformBits8(bd.white, t)
+	return bboard8{black, white, bitsKey8(black, BLACK) ^ bitsKey8(white, WHITE)}
+}
+
+// canonical follows the same rule as board.Board.Canonical
+func (bd bboard8) canonical() (bboard8, board.Transform) {
+	best, bestT := bd, board.Identity
+	for _, t := range board.Transforms[1:] {
+		if nbd := bd.transform(t); nbd.hash < best.hash {
+			best, bestT = nbd, t
+		}
+	}
+	return best, bestT
+}
@@ -0,0 +1,70 @@
+package builtinai
+
+import (
+	"math/rand"
+	"othello/board"
+	"testing"
+)
+
+func randomInput(r *rand.Rand, n int) (input string) {
+	for j := 0; j < n; j++ {
+		switch r.Intn(3) {
+		case 0:
+			input += "X"
+		case 1:
+			input += "O"
+		default:
+			input += "+"
+		}
+	}
+	return
+}
+
+func TestTransform(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		input8, input6 := randomInput(r, 64), randomInput(r, 36)
+		bd8, bd6 := board.NewBoardFromStr(input8), board.NewBoardFromStr(input6)
+		bbd8, bbd6 := newBboard8(input8), newBboard6(input6)
+
+		for _, tr := range board.Transforms {
+			want := bd8.Transform(tr)
+			if got := bbd8.transform(tr); got.String() != want.String() || got.hash != want.Hash() {
+				t.Fatal(tr, "\n", bd8.Visualize(), "\n", want.Visualize(), "\n", got.visualize())
+			}
+			if back := want.Transform(tr.Inverse()); back.String() != input8 {
+				t.Fatal(tr, "inverse\n", bd8.Visualize(), "\n", back.Visualize())
+			}
+			want = bd6.Transform(tr)
+			if got := bbd6.transform(tr); got.String() != want.String() || got.hash != want.Hash() {
+				t.Fatal(tr, "\n", bd6.Visualize(), "\n", want.Visualize(), "\n", got.visualize())
+			}
+		}
+
+		c8, t8 := bd8.Canonical()
+		if b, bt := bbd8.canonical(); b.String() != c8.String() || bt != t8 {
+			t.Fatal("canonical 8x8", t8, bt)
+		}
+		if c, _ := bd8.Transform(board.Transforms[r.Intn(8)]).Canonical(); c.String() != c8.String() {
+			t.Fatal("canonical depends on the orientation\n", bd8.Visualize())
+		}
+		c6, t6 := bd6.Canonical()
+		if b, bt := bbd6.canonical(); b.String() != c6.String() || bt != t6 {
+			t.Fatal("canonical 6x6", t6, bt)
+		}
+	}
+}
+
+func TestCanonicalOpening(t *testing.T) {
+	// "Cd" and its mirrored twins are the same opening
+	bd := board.NewBoard(8)
+	bd.PutStr(board.BLACK, "Cd")
+	canon, _ := bd.Canonical()
+	for _, s := range []string{"Dc", "Fe", "Ef"} {
+		bd := board.NewBoard(8)
+		bd.PutStr(board.BLACK, s)
+		if c, _ := bd.Canonical(); c.String() != canon.String() {
+			t.Error(s, "\n", c.Visualize(), "\n", canon.Visualize())
+		}
+	}
+}
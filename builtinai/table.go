@@ -0,0 +1,107 @@
+package builtinai
+
+import (
+	"othello/board"
+	"unsafe"
+)
+
+// the memory of a transposition table unless SetTableSize says otherwise
+const DEFAULT_TABLE_MB = 16
+
+// bound tells how a stored value relates to the real one
+type bound uint8
+
+const (
+	boundExact bound = iota
+	boundLower       // the search failed high, the value is at least this
+	boundUpper       // the search failed low, the value is at most this
+)
+
+type entry struct {
+	key   uint64
+	value int32
+	depth int32
+	move  int8
+	bound bound
+
+	// values of phase 1 are evaluations, of phase 2 disc differences
+	phase uint8
+
+	// the search that stored the entry, older ones are replaced first
+	gen uint8
+}
+
+// table is a transposition table of a fixed number of entries, one per slot.
+// An entry is only found again by a search of the phase that stored it, so the
+// exact results of the endgame survive the phase 1 iterations of a search.
+// An entry is replaced by one of a deeper or equal search, or by any entry of a
+// later search, so the table fills up with the current search without forgetting
+// the deep results of the previous ones.
+type table struct {
+	entries []entry
+	mask    uint64
+	gen     uint8
+
+	// what the values were computed for, they are meaningless for anything else
+	color color
+	rules board.Rules
+}
+
+// newTable returns a table of at most mb megabytes, or nil for none
+func newTable(mb int) *table {
+	n := uint64(mb) << 20 / uint64(unsafe.Sizeof(entry{}))
+	if n == 0 {
+		return nil
+	}
+	// a power of two so the slot is a mask of the key
+	size := uint64(1)
+	for size*2 <= n {
+		size *= 2
+	}
+	return &table{entries: make([]entry, size), mask: size - 1}
+}
+
+// prepare starts a new search, the entries are dropped when the AI plays
+// another color or rules than the last search
+func (t *table) prepare(cl color, r board.Rules) {
+	if t == nil {
+		return
+	}
+	if t.color != cl || t.rules != r {
+		for i := range t.entries {
+			t.entries[i] = entry{}
+		}
+		t.color, t.rules = cl, r
+	}
+	t.gen++
+}
+
+func (t *table) probe(key uint64, phase int) (entry, bool) {
+	if t == nil {
+		return entry{}, false
+	}
+	e := t.entries[key&t.mask]
+	return e, e.key == key && e.depth > 0 && int(e.phase) == phase
+}
+
+func (t *table) store(key uint64, phase, depth, value int, b bound, move int) {
+	if t == nil {
+		return
+	}
+	e := &t.entries[key&t.mask]
+	if e.gen == t.gen && e.key != key && int(e.depth) > depth {
+		return
+	}
+	*e = entry{key: key, value: int32(value), depth: int32(depth), move: int8(move), bound: b, gen: t.gen, phase: uint8(phase)}
+}
+
+// storeResult stores the value a search of the window alpha, beta found
+func (t *table) storeResult(key uint64, phase, depth, value, alpha, beta, move int) {
+	b := boundExact
+	if value <= alpha {
+		b = boundUpper
+	} else if value >= beta {
+		b = boundLower
+	}
+	t.store(key, phase, depth, value, b, move)
+}
@@ -0,0 +1,80 @@
+package builtinai
+
+import (
+	"math/rand"
+	"othello/board"
+	"testing"
+)
+
+func TestTable(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	for n := 0; n < 5; n++ {
+		pos, ok := randomPosition(r, SIZE8, 15)
+		if !ok {
+			continue
+		}
+
+		// the endgame is searched exactly with or without the table
+		with, without := NewAI8(board.BLACK, LV_FIVE), NewAI8(board.BLACK, LV_FIVE)
+		without.SetTableSize(0)
+		got, err := with.Search(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := without.Search(pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Exact || got.Score != want.Score || got.Nodes > want.Nodes {
+			t.Errorf("%s: with the table %+v, without %+v", pos, got, want)
+		}
+
+		// the table is kept for the next move of the same side
+		next, _, _ := pos.Play(got.Move)
+		if next.MustPass() {
+			continue
+		}
+		reply, err := NewAI8(board.BLACK, LV_FIVE).Search(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		next, _, _ = next.Play(reply.Move)
+		if next.IsOver() || next.MustPass() {
+			continue
+		}
+		again, err := with.Search(next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.Nodes >= got.Nodes/2 {
+			t.Errorf("%s: %d nodes after a search of %d", next, again.Nodes, got.Nodes)
+		}
+	}
+}
+
+func TestTablePhases(t *testing.T) {
+	tb := newTable(1)
+	tb.prepare(BLACK, board.Standard)
+	tb.store(42, 2, MAXINT, 6, boundExact, 3)
+	tb.store(43, 1, 4, 100, boundLower, 5)
+
+	// a new search of either phase keeps the entries, each found by its own phase
+	tb.prepare(BLACK, board.Standard)
+	if e, ok := tb.probe(42, 2); !ok || e.value != 6 || e.move != 3 {
+		t.Error("phase 2 entry", e, ok)
+	}
+	if _, ok := tb.probe(42, 1); ok {
+		t.Error("phase 2 entry found by phase 1")
+	}
+	if e, ok := tb.probe(43, 1); !ok || e.value != 100 {
+		t.Error("phase 1 entry", e, ok)
+	}
+	if _, ok := tb.probe(43, 2); ok {
+		t.Error("phase 1 entry found by phase 2")
+	}
+
+	tb.prepare(WHITE, board.Standard)
+	if _, ok := tb.probe(42, 2); ok {
+		t.Error("entry kept for the other color")
+	}
+}
@@ -0,0 +1,72 @@
+package builtinai
+
+import "othello/board"
+
+// zobrist keys of the bitboards, they are the same keys as board.Board.Hash
+// so a position has one key whichever representation it is stored in
+
+var (
+	// key of a single disc, [0] for black and [1] for white
+	zobrist6 [2][36]uint64
+	zobrist8 [2][64]uint64
+
+	// keys of all the discs in one byte of a bitboard
+	byteKeys6 [2][5][256]uint64
+	byteKeys8 [2][8][256]uint64
+)
+
+func init() {
+	for loc := 0; loc < 36; loc++ {
+		zobrist6[0][loc] = board.ZobristSquare(board.BLACK, loc%SIZE6, loc/SIZE6)
+		zobrist6[1][loc] = board.ZobristSquare(board.WHITE, loc%SIZE6, loc/SIZE6)
+	}
+	for loc := 0; loc < 64; loc++ {
+		zobrist8[0][loc] = board.ZobristSquare(board.BLACK, loc%SIZE8, loc/SIZE8)
+		zobrist8[1][loc] = board.ZobristSquare(board.WHITE, loc%SIZE8, loc/SIZE8)
+	}
+	for cl := 0; cl < 2; cl++ {
+		for b := 0; b < 8; b++ {
+			for v := 0; v < 256; v++ {
+				for i := 0; i < 8; i++ {
+					if v&(1<<i) == 0 {
+						continue
+					}
+					loc := b*8 + i
+					if loc < 36 {
+						byteKeys6[cl][b][v] ^= zobrist6[cl][loc]
+					}
+					byteKeys8[cl][b][v] ^= zobrist8[cl][loc]
+				}
+			}
+		}
+	}
+}
+
+func zobristIndex(cl color) int {
+	if cl == BLACK {
+		return 0
+	}
+	return 1
+}
+
+// keys of the discs in bits, as if they were all cl
+func bitsKey6(bits uint64, cl color) (key uint64) {
+	idx := zobristIndex(cl)
+	for b := 0; b < 5; b++ {
+		key ^= byteKeys6[idx][b][(bits>>(b*8))&0xFF]
+	}
+	return
+}
+
+func bitsKey8(bits uint64, cl color) (key uint64) {
+	idx := zobristIndex(cl)
+	for b := 0; b < 8; b++ {
+		key ^= byteKeys8[idx][b][(bits>>(b*8))&0xFF]
+	}
+	return
+}
+
+// key of the side to move
+func turnKey(cl color) uint64 {
+	return board.ZobristTurn(board.Color(cl))
+}
@@ -0,0 +1,42 @@
+package builtinai
+
+import (
+	"math/rand"
+	"othello/board"
+	"testing"
+)
+
+func TestZobrist(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for game := 0; game < 200; game++ {
+		bd := board.NewBoard(8)
+		bbd := newBboard8(bd.String())
+		bd6 := board.NewBoard(6)
+		bbd6 := newBboard6(bd6.String())
+		cl := board.BLACK
+		for !bd.IsOver() {
+			if valid := bd.AllValidPoint(cl); len(valid) > 0 {
+				p := valid[r.Intn(len(valid))]
+				bd.PutPoint(cl, p)
+				bbd.put(color(cl), p.Y*SIZE8+p.X)
+			}
+			if valid := bd6.AllValidPoint(cl); len(valid) > 0 {
+				p := valid[r.Intn(len(valid))]
+				bd6.PutPoint(cl, p)
+				bbd6.put(color(cl), p.Y*SIZE6+p.X)
+			}
+			cl = cl.Opponent()
+
+			if bd.Hash() != bbd.hash || bbd.hash != newBboard8(bbd.String()).hash {
+				t.Fatal("8x8 keys differ\n", bd.Visualize())
+			}
+			if bd6.Hash() != bbd6.hash || bbd6.hash != newBboard6(bbd6.String()).hash {
+				t.Fatal("6x6 keys differ\n", bd6.Visualize())
+			}
+			// the side to move is part of the key
+			if board.NewPosition(bd, cl).Hash() != bbd.key(color(cl)) || board.NewPosition(bd6, cl).Hash() != bbd6.key(color(cl)) {
+				t.Fatal("the keys with", cl, "to move differ")
+			}
+		}
+	}
+}
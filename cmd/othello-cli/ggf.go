@@ -0,0 +1,74 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"othello/board"
+	"othello/ggf"
+)
+
+func ggfCmd(args []string) error {
+	fs := flag.NewFlagSet("ggf", flag.ExitOnError)
+	in := fs.String("in", "", "GGF file to print, - for stdin")
+	transcript := fs.String("transcript", "", "transcript such as f5d6c3 to convert to GGF")
+	size := fs.Int("size", 8, "board size of -transcript")
+	out := fs.String("out", "", "file to write the GGF of -transcript to, stdout if empty")
+	fs.Parse(args)
+
+	switch {
+	case *in != "":
+		return printGGF(*in)
+	case *transcript != "":
+		if !board.ValidSize(*size) {
+			return fmt.Errorf("invalid board size %d", *size)
+		}
+		rec, err := board.ParseTranscript(board.NewBoard(*size), board.BLACK, *transcript)
+		if err != nil {
+			return err
+		}
+		w := io.Writer(os.Stdout)
+		if *out != "" {
+			f, err := os.Create(*out)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+		g, err := ggf.FromRecord(rec)
+		if err != nil {
+			return err
+		}
+		return ggf.Write(w, g)
+	default:
+		return fmt.Errorf("either -in or -transcript is required")
+	}
+}
+
+func printGGF(path string) error {
+	r := io.Reader(os.Stdin)
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	games, err := ggf.Read(r)
+	if err != nil {
+		return err
+	}
+	for i, g := range games {
+		rec, err := g.Record()
+		if err != nil {
+			return fmt.Errorf("game %d: %v", i+1, err)
+		}
+		fmt.Printf("game %d: %s (black) vs %s (white), result %s\n", i+1, g.Black, g.White, g.Result)
+		fmt.Println(rec.Transcript())
+		fmt.Print(rec.Board().Visualize())
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+// othello-cli runs the board and engine tools without the GUI
+//
+//	othello-cli perft [-size 8] [-depth 6] [-board ...] [-color black] [-slow] [-divide]
+//	othello-cli ggf -in games.ggf
+//	othello-cli ggf -transcript f5d6c3 [-size 8] [-out game.ggf]
+//	othello-cli wthor -wtb WTH_2023.wtb [-jou WTHOR.JOU] [-trn WTHOR.TRN]
+//	othello-cli match [-black builtin:3] [-white ./ai] [-size 8] [-games 2] [-timeout 10s] [-threads 0] [-rules anti] [-out games.ggf]
+//	othello-cli match -black builtin:3 -white ./ai -red builtin:2 [-blue builtin:1] [-size 8]
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"perft": {"count the leaves of the game tree", perftCmd},
+	"ggf":   {"print GGF games or convert a transcript to GGF", ggfCmd},
+	"wthor": {"convert a WTHOR database to GGF", wthorCmd},
+	"match": {"play games between AIs without the GUI", matchCmd},
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: othello-cli <command> [flags]")
+	fmt.Fprintln(os.Stderr, "commands:")
+	var names []string
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
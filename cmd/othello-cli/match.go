@@ -0,0 +1,213 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"othello/board"
+	"othello/builtinai"
+	"othello/external"
+	"othello/ggf"
+	"strings"
+	"time"
+)
+
+// player chooses moves for one side of a match
+type player interface {
+	Move(board.Position) (board.Point, error)
+	Close()
+}
+
+// newPlayer reads "builtin:<level>" for a built-in AI of level 1 to 5,
+// anything else is the path of an external AI. A built-in AI given a timeout
+// deepens its search to use most of it, and searches on threads goroutines.
+func newPlayer(spec string, size int, rules board.Rules, timeout time.Duration, threads int) (player, error) {
+	if strings.HasPrefix(spec, "builtin:") {
+		lv, err := parseLevel(spec)
+		if err != nil {
+			return nil, err
+		}
+		ai, err := builtinai.NewEngine(size, lv)
+		if err != nil {
+			return nil, err
+		}
+		ai.SetRules(rules)
+		ai.SetMoveTime(timeout * 3 / 4)
+		ai.SetThreads(threads)
+		ai.SetInfo(builtinai.PrintInfo)
+		return ai, nil
+	}
+	return external.Start(spec, os.Stderr)
+}
+
+// parseLevel reads the level of "builtin:<level>"
+func parseLevel(spec string) (builtinai.Level, error) {
+	var lv int
+	if _, err := fmt.Sscanf(spec, "builtin:%d", &lv); err != nil || lv < 1 || lv > 5 {
+		return 0, fmt.Errorf("invalid built-in AI %q, the level must be 1 to 5", spec)
+	}
+	return builtinai.Level(lv - 1), nil
+}
+
+func matchCmd(args []string) error {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	black := fs.String("black", "builtin:1", "black player, builtin:<level 1-5> or the path of an external AI")
+	white := fs.String("white", "builtin:1", "white player, builtin:<level 1-5> or the path of an external AI")
+	size := fs.Int("size", 8, "board size")
+	games := fs.Int("games", 1, "games to play, the players swap colors after every game")
+	timeout := fs.Duration("timeout", 0, "time allowed for every move, 0 for no limit")
+	rulesStr := fs.String("rules", "standard", "standard or anti")
+	scoringStr := fs.String("scoring", "empties", "what the empty squares of a game that ends early count for, empties to the winner or discs only")
+	threads := fs.Int("threads", 1, "goroutines of every built-in AI, 0 for one per CPU")
+	out := fs.String("out", "", "file to append the games to in GGF")
+	red := fs.String("red", "", "red player for a game of Rolit with three or more players")
+	blue := fs.String("blue", "", "blue player for a game of Rolit with four players, -red is needed too")
+	fs.Parse(args)
+
+	if !board.ValidSize(*size) {
+		return fmt.Errorf("invalid board size %d", *size)
+	}
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	switch {
+	case *blue != "" && *red == "":
+		return fmt.Errorf("-blue needs -red")
+	case *red != "" && (set["rules"] || set["scoring"] || set["out"]):
+		return fmt.Errorf("-rules, -scoring and -out are not for Rolit")
+	case *blue != "":
+		return rolitMatch([]string{*black, *white, *red, *blue}, *size, *games, *timeout)
+	case *red != "":
+		return rolitMatch([]string{*black, *white, *red}, *size, *games, *timeout)
+	}
+	rules := board.Standard
+	switch *rulesStr {
+	case "standard":
+	case "anti":
+		rules = board.Anti
+	default:
+		return fmt.Errorf("unknown rules %q", *rulesStr)
+	}
+	scoring := board.EmptiesToWinner
+	switch *scoringStr {
+	case "empties":
+	case "discs":
+		scoring = board.DiscsOnly
+	default:
+		return fmt.Errorf("unknown scoring %q", *scoringStr)
+	}
+
+	var w *os.File
+	if *out != "" {
+		var err error
+		if w, err = os.OpenFile(*out, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+
+	names := [2]string{*black, *white}
+	var wins [2]int
+	for n := 0; n < *games; n++ {
+		// names[0] plays black in even games
+		first, second := names[n%2], names[1-n%2]
+		rec, err := playMatchGame(first, second, *size, rules, *timeout, *threads)
+		if err != nil {
+			return err
+		}
+
+		var result string
+		switch winner := rec.Winner(rules); winner {
+		case board.BLACK:
+			wins[n%2]++
+			result = first + " won"
+		case board.WHITE:
+			wins[1-n%2]++
+			result = second + " won"
+		default:
+			result = "draw"
+		}
+		if end := rec.End(); end.Forfeit() {
+			result += " by " + end.String()
+		} else {
+			b, wh := rec.Board().FinalScore(scoring)
+			result += fmt.Sprintf(", %d-%d", b, wh)
+		}
+		fmt.Printf("game %d: %s (black) vs %s (white): %s\n", n+1, first, second, result)
+
+		if w != nil {
+			g, err := ggf.FromRecord(rec)
+			if err != nil {
+				return err
+			}
+			g.Black, g.White = first, second
+			if end := rec.End(); !end.Forfeit() {
+				b, wh := rec.Board().FinalScore(scoring)
+				g.Result = ggf.FormatResult(b - wh)
+			}
+			g.Date = time.Now().Format("2006.01.02_15:04:05.MST")
+			if rules == board.Anti {
+				g.Type += "a"
+			}
+			if err := ggf.Write(w, g); err != nil {
+				return err
+			}
+		}
+	}
+	fmt.Printf("%s %d, %s %d, draws %d\n", names[0], wins[0], names[1], wins[1], *games-wins[0]-wins[1])
+	return nil
+}
+
+// playMatchGame plays one game to its end, a player that fails to give a valid move
+// in time loses it
+func playMatchGame(blackSpec, whiteSpec string, size int, rules board.Rules, timeout time.Duration, threads int) (*board.Game, error) {
+	var players [2]player
+	for i, spec := range []string{blackSpec, whiteSpec} {
+		p, err := newPlayer(spec, size, rules, timeout, threads)
+		if err != nil {
+			return nil, err
+		}
+		defer p.Close()
+		players[i] = p
+	}
+
+	type reply struct {
+		p   board.Point
+		err error
+	}
+	rec := board.NewGame(board.NewBoard(size), board.BLACK)
+	for !rec.IsOver() {
+		if rec.MustPass() {
+			rec.Pass()
+			continue
+		}
+		now := rec.Turn()
+		pl := players[0]
+		if now == board.WHITE {
+			pl = players[1]
+		}
+
+		pos := rec.Position()
+		c := make(chan reply, 1)
+		go func() {
+			p, err := pl.Move(pos)
+			c <- reply{p, err}
+		}()
+		var limit <-chan time.Time
+		if timeout > 0 {
+			limit = time.After(timeout)
+		}
+		select {
+		case r := <-c:
+			if r.err == nil && !rec.Play(r.p) {
+				r.err = fmt.Errorf("%s is not a valid move", r.p.Algebraic())
+			}
+			if r.err != nil {
+				fmt.Fprintf(os.Stderr, "%v: %v\n", now, r.err)
+				rec.Disqualify(now)
+			}
+		case <-limit:
+			rec.TimeOut(now)
+		}
+	}
+	return rec, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"othello/board"
+	"othello/builtinai"
+	"time"
+)
+
+func perftCmd(args []string) error {
+	fs := flag.NewFlagSet("perft", flag.ExitOnError)
+	size := fs.Int("size", 8, "board size, ignored when -board is given")
+	depth := fs.Int("depth", 6, "plies to search")
+	bdStr := fs.String("board", "", "position to start from, a board string or a layout with rows separated by '/', the starting position if empty")
+	clStr := fs.String("color", "black", "side to move, black or white")
+	slow := fs.Bool("slow", false, "use board.Board instead of the built-in AI bitboards")
+	divide := fs.Bool("divide", false, "print the count below every legal move")
+	fs.Parse(args)
+
+	if !board.ValidSize(*size) {
+		return fmt.Errorf("invalid board size %d", *size)
+	}
+	bd := board.NewBoard(*size)
+	if *bdStr != "" {
+		var err error
+		if bd, err = board.ParseBoard(*bdStr); err != nil {
+			pos, lerr := board.ParseLayout(*bdStr)
+			if lerr != nil {
+				return err
+			}
+			bd = pos.Board
+		}
+	}
+	cl := board.BLACK
+	switch *clStr {
+	case "black":
+	case "white":
+		cl = board.WHITE
+	default:
+		return fmt.Errorf("unknown color %q", *clStr)
+	}
+
+	perft := builtinai.Perft
+	if *slow {
+		perft = board.Perft
+	}
+
+	start := time.Now()
+	var total uint64
+	if *divide && *depth > 0 {
+		for _, p := range bd.AllValidPoint(cl) {
+			tmp := bd.Copy()
+			tmp.PutWithoutCheck(cl, p)
+			count := perft(tmp, cl.Opponent(), *depth-1)
+			fmt.Printf("%s: %d\n", p.PointToStr(), count)
+			total += count
+		}
+		if len(bd.AllValidPoint(cl)) == 0 {
+			total = perft(bd, cl, *depth)
+		}
+	} else {
+		total = perft(bd, cl, *depth)
+	}
+	spent := time.Since(start)
+
+	fmt.Printf("perft(%d) = %d, %v, %.0f leaves/s\n", *depth, total, spent, float64(total)/spent.Seconds())
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"othello/board"
+	"othello/builtinai"
+	"othello/external"
+	"strings"
+	"time"
+)
+
+// newRolitPlayer is newPlayer for a game of Rolit, the built-in AI plays any board
+func newRolitPlayer(spec string, players int) (player, error) {
+	if strings.HasPrefix(spec, "builtin:") {
+		lv, err := parseLevel(spec)
+		if err != nil {
+			return nil, err
+		}
+		ai := builtinai.NewRolitAI(players, lv)
+		ai.SetInfo(builtinai.PrintInfo)
+		return ai, nil
+	}
+	return external.Start(spec, os.Stderr)
+}
+
+// rolitMatch plays games of Rolit between names, which take the seats in turn order
+// and move one seat on after every game
+func rolitMatch(names []string, size, games int, timeout time.Duration) error {
+	wins := make([]int, len(names))
+	for n := 0; n < games; n++ {
+		// names[i] plays the seat i+n
+		seated := make([]string, len(names))
+		for i, name := range names {
+			seated[(i+n)%len(names)] = name
+		}
+		rec, err := playRolitGame(seated, size, timeout)
+		if rec == nil {
+			return err
+		}
+
+		var sb strings.Builder
+		for i, name := range seated {
+			if i > 0 {
+				sb.WriteString(" vs ")
+			}
+			fmt.Fprintf(&sb, "%s (%v)", name, board.Seats[i])
+		}
+		var result string
+		switch {
+		case err != nil:
+			result = "stopped, " + err.Error()
+		case len(rec.Winners()) > 1:
+			result = "draw"
+		default:
+			winner := rec.Winners()[0]
+			wins[(winner.Number()-1-n%len(names)+len(names))%len(names)]++
+			result = seated[winner.Number()-1] + " won"
+		}
+		if err == nil {
+			var scores []string
+			for _, score := range rec.Scores() {
+				scores = append(scores, fmt.Sprint(score))
+			}
+			result += ", " + strings.Join(scores, "-")
+		}
+		fmt.Printf("game %d: %s: %s\n", n+1, sb.String(), result)
+	}
+	for i, name := range names {
+		fmt.Printf("%s %d, ", name, wins[i])
+	}
+	fmt.Println("draws or stopped", games-sum(wins))
+	return nil
+}
+
+func sum(ns []int) int {
+	total := 0
+	for _, n := range ns {
+		total += n
+	}
+	return total
+}
+
+// playRolitGame plays one game to its end, there is no forfeit with more than two
+// players so a player that fails to give a valid move in time stops the game
+func playRolitGame(specs []string, size int, timeout time.Duration) (*board.Rolit, error) {
+	players := make([]player, len(specs))
+	for i, spec := range specs {
+		p, err := newRolitPlayer(spec, len(specs))
+		if err != nil {
+			return nil, err
+		}
+		defer p.Close()
+		players[i] = p
+	}
+
+	type reply struct {
+		p   board.Point
+		err error
+	}
+	rec := board.NewRolit(board.NewRolitBoard(size, len(specs)), len(specs))
+	for !rec.IsOver() {
+		now := rec.Turn()
+		pl := players[now.Number()-1]
+
+		pos := rec.Position()
+		c := make(chan reply, 1)
+		go func() {
+			p, err := pl.Move(pos)
+			c <- reply{p, err}
+		}()
+		var limit <-chan time.Time
+		if timeout > 0 {
+			limit = time.After(timeout)
+		}
+		select {
+		case r := <-c:
+			if r.err == nil && !rec.Play(r.p) {
+				r.err = fmt.Errorf("%s is not a valid move", r.p.Algebraic())
+			}
+			if r.err != nil {
+				fmt.Fprintf(os.Stderr, "%v: %v\n", now, r.err)
+				return rec, fmt.Errorf("%v failed to move", now)
+			}
+		case <-limit:
+			return rec, fmt.Errorf("%v ran out of time", now)
+		}
+	}
+	return rec, nil
+}
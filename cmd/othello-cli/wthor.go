@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"othello/ggf"
+	"othello/wthor"
+	"strconv"
+)
+
+func wthorCmd(args []string) error {
+	fs := flag.NewFlagSet("wthor", flag.ExitOnError)
+	wtb := fs.String("wtb", "", "games file such as WTH_2023.wtb")
+	jou := fs.String("jou", "", "players file WTHOR.JOU, optional")
+	trn := fs.String("trn", "", "tournaments file WTHOR.TRN, optional")
+	fs.Parse(args)
+
+	if *wtb == "" {
+		return fmt.Errorf("-wtb is required")
+	}
+	db, err := wthor.Load(*wtb, *jou, *trn)
+	if err != nil {
+		return err
+	}
+	for i, g := range db.Games {
+		rec, err := g.Record()
+		if err != nil {
+			return fmt.Errorf("game %d: %v", i+1, err)
+		}
+		gg, err := ggf.FromRecord(rec)
+		if err != nil {
+			return fmt.Errorf("game %d: %v", i+1, err)
+		}
+		gg.Place = db.Tournament(g.Tournament)
+		gg.Date = strconv.Itoa(g.Year)
+		gg.Black = db.Player(g.Black)
+		gg.White = db.Player(g.White)
+		// the final score counts empty squares for the winner, which the board does not know
+		gg.Result = ggf.FormatResult(2*g.BlackDiscs - 64)
+		if err := ggf.Write(os.Stdout, gg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
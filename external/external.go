@@ -0,0 +1,144 @@
+// Package external runs external AIs, programs that read a position per line on
+// stdin, in the "<board> <side>" format of board.Position.String, and reply with
+// their move on stdout in either notation. Anything after the move is ignored.
+package external
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"othello/board"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the stderr of an AI kept for the log
+const stderrKept = 4096
+
+// AI is a running external AI
+type AI struct {
+	cmd *exec.Cmd
+	in  *bufio.Writer
+	out *bufio.Reader
+
+	// the end of what the AI wrote on stderr
+	stderr tail
+
+	close sync.Once
+
+	// LogFile, if set, is written with what went wrong when the AI fails to give a
+	// valid move: its stderr, the position and its reply
+	LogFile string
+}
+
+// Start runs the program at path, what it writes on stderr is copied to stderr
+// unless that is nil
+func Start(path string, stderr io.Writer) (*AI, error) {
+	ai := &AI{cmd: exec.Command(path)}
+	setProcAttr(ai.cmd)
+	in, err := ai.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := ai.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	ai.cmd.Stderr = &ai.stderr
+	if stderr != nil {
+		ai.cmd.Stderr = io.MultiWriter(&ai.stderr, stderr)
+	}
+	if err := ai.cmd.Start(); err != nil {
+		return nil, err
+	}
+	ai.in, ai.out = bufio.NewWriter(in), bufio.NewReader(out)
+	return ai, nil
+}
+
+// Move sends pos and returns the reply, which has to be a valid move of the side to move
+func (ai *AI) Move(pos board.Position) (board.Point, error) {
+	fmt.Fprintln(ai.in, pos.String())
+	if err := ai.in.Flush(); err != nil {
+		return board.Point{X: -1, Y: -1}, ai.fail(pos, "", fmt.Sprintf("position not sent: %v", err))
+	}
+	line, _ := ai.out.ReadString('\n')
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return board.Point{X: -1, Y: -1}, ai.fail(pos, line, "no output")
+	}
+	p, err := board.ParsePoint(fields[0])
+	if err != nil || !pos.Board.Contains(p) {
+		return board.Point{X: -1, Y: -1}, ai.fail(pos, line, fmt.Sprintf("unknown output %q", fields[0]))
+	}
+	if !valid(pos, p) {
+		return board.Point{X: -1, Y: -1}, ai.fail(pos, line, fmt.Sprintf("%s is not a valid move", fields[0]))
+	}
+	return p, nil
+}
+
+// valid reports whether p is a valid move of the side to move, under the Rolit
+// rules when the board has red or blue discs or one of them is to move
+func valid(pos board.Position, p board.Point) bool {
+	bd, cl := pos.Board, pos.ToMove
+	if cl != board.RED && cl != board.BLUE && bd.CountPieces(board.RED)+bd.CountPieces(board.BLUE) == 0 {
+		_, _, ok := pos.Play(p)
+		return ok
+	}
+	for _, q := range bd.RolitMoves(cl) {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Close stops the AI, it may be called more than once
+func (ai *AI) Close() {
+	ai.close.Do(func() {
+		kill(ai.cmd)
+		ai.cmd.Wait()
+	})
+}
+
+// fail returns the error of a reply that is not a move, after writing the log
+func (ai *AI) fail(pos board.Position, reply, reason string) error {
+	err := fmt.Errorf("external AI %s: %s", ai.cmd.Path, reason)
+	if ai.LogFile == "" {
+		return err
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s\n%s\n\n%s\n\n", time.Now().Format("2006/01/02 15:04:05"), ai.cmd.Path, reason)
+	fmt.Fprintf(&sb, "stderr:\n%s\n", ai.stderr.String())
+	fmt.Fprintf(&sb, "last state of board:\n%s\n", pos.Board.Visualize())
+	fmt.Fprintf(&sb, "last stdin:\n%s\n", pos.String())
+	fmt.Fprintf(&sb, "last stdout:\n%s\n", reply)
+	if werr := os.WriteFile(ai.LogFile, []byte(sb.String()), 0644); werr != nil {
+		return fmt.Errorf("%v, and the log was not written: %v", err, werr)
+	}
+	return fmt.Errorf("%v, see %s", err, ai.LogFile)
+}
+
+// tail keeps the last stderrKept bytes written to it
+type tail struct {
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (t *tail) Write(b []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, b...)
+	if len(t.buf) > stderrKept {
+		t.buf = append(t.buf[:0], t.buf[len(t.buf)-stderrKept:]...)
+	}
+	return len(b), nil
+}
+
+func (t *tail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
@@ -0,0 +1,127 @@
+package external
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"othello/board"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// the test binary is the external AI too, FAKE_AI says how it replies
+func TestMain(m *testing.M) {
+	switch os.Getenv("FAKE_AI") {
+	case "":
+		os.Exit(m.Run())
+	case "first":
+		in := bufio.NewScanner(os.Stdin)
+		for in.Scan() {
+			fmt.Println(readPosition(in.Text()).ValidMoves()[0].Algebraic(), "and a comment")
+		}
+	case "rolit":
+		in := bufio.NewScanner(os.Stdin)
+		for in.Scan() {
+			pos := readPosition(in.Text())
+			fmt.Println(pos.Board.RolitMoves(pos.ToMove)[0].Algebraic())
+		}
+	case "garbage":
+		fmt.Println("zz")
+	}
+	os.Exit(0)
+}
+
+// readPosition reads what Move sends, square boards are not sent as layouts
+func readPosition(s string) board.Position {
+	pos, err := board.ParsePosition(s)
+	if err != nil {
+		pos, err = board.ParseLayout(s)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	return pos
+}
+
+func start(t *testing.T, mode string) *AI {
+	t.Setenv("FAKE_AI", mode)
+	ai, err := Start(os.Args[0], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(ai.Close)
+	return ai
+}
+
+func TestMove(t *testing.T) {
+	ai := start(t, "first")
+	g := board.NewGame(board.NewBoard(8), board.BLACK)
+	for i := 0; i < 10 && !g.IsOver(); i++ {
+		p, err := ai.Move(g.Position())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !g.Play(p) {
+			t.Fatal(p, "was played")
+		}
+	}
+	ai.Close()
+	ai.Close()
+}
+
+func TestFail(t *testing.T) {
+	ai := start(t, "garbage")
+	ai.LogFile = filepath.Join(t.TempDir(), "error.log")
+	pos := board.NewPosition(board.NewBoard(8), board.BLACK)
+	if _, err := ai.Move(pos); err == nil || !strings.Contains(err.Error(), `unknown output "zz"`) {
+		t.Fatal(err)
+	}
+	log, err := os.ReadFile(ai.LogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(log), pos.String()) || !strings.Contains(string(log), "zz") {
+		t.Error(string(log))
+	}
+	// the AI has quit
+	if _, err := ai.Move(pos); err == nil {
+		t.Error("no error without a reply")
+	}
+}
+
+func TestTail(t *testing.T) {
+	var tl tail
+	tl.Write([]byte("lost"))
+	tl.Write([]byte(strings.Repeat("x", stderrKept-1)))
+	tl.Write([]byte("y"))
+	if s := tl.String(); len(s) != stderrKept || s[0] != 'x' || s[len(s)-1] != 'y' {
+		t.Errorf("%d bytes %q...%q", len(s), s[:1], s[len(s)-1:])
+	}
+}
+
+func TestTallBoard(t *testing.T) {
+	ai := start(t, "first")
+	pos, err := board.ParseLayout("++++++/++++++/++++++/++++++/++++++/++++++/++OX++/++XO++ 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the first valid move is on the seventh row, past the width
+	p, err := ai.Move(pos)
+	if err != nil || p != board.NewPoint(1, 6) {
+		t.Error(p.Algebraic(), err)
+	}
+}
+
+func TestRolit(t *testing.T) {
+	ai := start(t, "rolit")
+	// red has nothing to flip, any square next to a disc will do
+	pos, err := board.ParseLayout("++++/+XO+/+OX+/++++ 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p, err := ai.Move(pos); err != nil || p != board.NewPoint(0, 0) {
+		t.Error(p.Algebraic(), err)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build !windows
+// +build !windows
+
+package external
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// the AI gets a process group of its own, so Close stops whatever it started too
+func setProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func kill(cmd *exec.Cmd) {
+	syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
@@ -0,0 +1,19 @@
+//go:build windows
+// +build windows
+
+package external
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+func setProcAttr(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+}
+
+// taskkill /T stops whatever the AI started too
+func kill(cmd *exec.Cmd) {
+	exec.Command("taskkill", "/F", "/T", "/PID", fmt.Sprint(cmd.Process.Pid)).Run()
+}
@@ -2,9 +2,10 @@ package game
 
 import (
 	"fmt"
+	"math/rand"
 	"othello/board"
 	"othello/builtinai"
-	"strings"
+	"othello/ggf"
 	"time"
 
 	"fyne.io/fyne/v2"
@@ -20,16 +21,19 @@ const (
 	timerTextSize   = 13
 	nameTextSize    = 13
 	maxNameLen      = 20
+
+	// the grid never grows beyond 8 units of the full size
+	maxUnitSize = 48
+	gridSize    = 8 * maxUnitSize
 )
 
 var (
 	nullPoint = board.NewPoint(-1, -1)
-	unitSize  = fyne.NewSize(48, 48)
 )
 
 type game struct {
 	window fyne.Window
-	bd     board.Board
+	rec    *board.Game
 	units  [][]*unit
 
 	counterBlack Text
@@ -38,42 +42,29 @@ type game struct {
 	passBtn *widget.Button
 	com1    computer
 	com2    computer
-	now     board.Color
 
 	blackSpent time.Duration
 	whiteSpent time.Duration
 
+	// time spent on every move of rec and when the current one started
+	moveTimes []time.Duration
+	moveStart time.Time
+
+	// the moves of a loaded game, their evaluations are saved again
+	loaded []ggf.Move
+
 	haveHuman bool
 	over      bool
+
+	rules   board.Rules
+	scoring board.Scoring
 }
 
 func newNameText(winSize fyne.Size, params Parameter) *fyne.Container {
-	var name string
-
-	if params.BlackAgent == AgentHuman {
-		name = "human"
-	} else if params.BlackAgent == AgentBuiltIn {
-		name = "AI: " + params.BlackAILevel.String()
-	} else {
-		path := strings.Split(params.BlackPath, "/")
-		if len(path) != 0 {
-			name = "AI: " + path[len(path)-1]
-		}
-	}
-	left := NewText(name, nameTextSize, fyne.TextAlignLeading)
+	left := NewText(params.BlackName(), nameTextSize, fyne.TextAlignLeading)
 	left.SetMaxSize(winSize.Width / 2)
 
-	if params.WhiteAgent == AgentHuman {
-		name = "human"
-	} else if params.WhiteAgent == AgentBuiltIn {
-		name = "AI: " + params.WhiteAILevel.String()
-	} else {
-		path := strings.Split(params.WhitePath, "/")
-		if len(path) != 0 {
-			name = "AI: " + path[len(path)-1]
-		}
-	}
-	right := NewText(name, nameTextSize, fyne.TextAlignTrailing)
+	right := NewText(params.WhiteName(), nameTextSize, fyne.TextAlignTrailing)
 	right.SetMaxSize(winSize.Width / 2)
 
 	return container.NewGridWithColumns(2, left.CanvasText(), right.CanvasText())
@@ -86,45 +77,83 @@ func newCounterText() (Text, Text) {
 	return counter1, counter2
 }
 
-func New(a fyne.App, window fyne.Window, menu *fyne.Container, params Parameter, size int) *fyne.Container {
-	g := &game{}
+func newUnitSize(size int) fyne.Size {
+	side := float32(gridSize) / float32(size)
+	if side > maxUnitSize {
+		side = maxUnitSize
+	}
+	return fyne.NewSize(side, side)
+}
 
-	units := make([][]*unit, size)
+// newGrid returns the units of bd indexed by [x][y], in a grid filled row by row
+// as the board may be rectangular
+func newGrid(bd *board.Board, onTapped func(p board.Point)) (*fyne.Container, [][]*unit) {
+	width, height := bd.Width(), bd.Height()
+	unitSize := newUnitSize(width)
+	if height > width {
+		unitSize = newUnitSize(height)
+	}
+	units := make([][]*unit, width)
 	for i := range units {
-		units[i] = make([]*unit, size)
+		units[i] = make([]*unit, height)
 	}
-	grid := container.New(layout.NewGridLayout(size))
-	for i := 0; i < size; i++ {
-		for j := 0; j < size; j++ {
-			u := newUnit(g, board.NONE, i, j)
+	grid := container.New(layout.NewGridLayout(width))
+	for j := 0; j < height; j++ {
+		for i := 0; i < width; i++ {
+			u := newUnit(board.NONE, i, j, unitSize, onTapped)
 			grid.Add(u)
 			units[i][j] = u
 		}
 	}
+	return grid, units
+}
 
-	if params.BlackAgent == AgentBuiltIn {
-		if size == 6 {
-			g.com1 = builtinai.NewAI6(builtinai.BLACK, params.BlackAILevel)
-		} else {
-			g.com1 = builtinai.NewAI8(builtinai.BLACK, params.BlackAILevel)
+// newRecord returns the record a game is played in, a loaded one, a custom start or a
+// new board of size x size with Blocks random blocked squares
+func newRecord(params Parameter, size int) *board.Game {
+	switch {
+	case params.Record != nil:
+		return params.Record.Copy()
+	case params.Start != nil:
+		return board.NewGame(params.Start, params.GoesFirst)
+	default:
+		bd := board.NewBoardWith(size, params.Opening)
+		bd.BlockRandom(params.Blocks, rand.New(rand.NewSource(time.Now().UnixNano())))
+		return board.NewGame(bd, params.GoesFirst)
+	}
+}
+
+func New(a fyne.App, window fyne.Window, menu *fyne.Container, params Parameter, size int) *fyne.Container {
+	g := &game{}
+	g.rec = newRecord(params, size)
+	if params.Record != nil {
+		g.moveTimes = make([]time.Duration, g.rec.Ply())
+		for i, m := range params.RecordMoves {
+			if i < len(g.moveTimes) {
+				g.moveTimes[i] = m.Time
+			}
 		}
+		g.loaded = params.RecordMoves
+	}
+
+	grid, units := newGrid(g.rec.Board(), g.tapped)
+	width := g.rec.Board().Width()
+
+	if params.BlackAgent == AgentBuiltIn {
+		g.com1 = newBuiltIn(width, params.BlackAILevel, params.Rules)
 	} else if params.BlackAgent == AgentExternal {
-		g.com1 = newCom(board.BLACK, params.BlackPath)
+		g.com1 = newCom(params.BlackPath)
 	}
 	if params.WhiteAgent == AgentBuiltIn {
-		if size == 6 {
-			g.com2 = builtinai.NewAI6(builtinai.WHITE, params.WhiteAILevel)
-		} else {
-			g.com2 = builtinai.NewAI8(builtinai.WHITE, params.WhiteAILevel)
-		}
+		g.com2 = newBuiltIn(width, params.WhiteAILevel, params.Rules)
 	} else if params.WhiteAgent == AgentExternal {
-		g.com2 = newCom(board.WHITE, params.WhitePath)
+		g.com2 = newCom(params.WhitePath)
 	}
 
 	g.window = window
 	g.units = units
-	g.now = params.GoesFirst
-	g.bd = board.NewBoard(size)
+	g.rules = params.Rules
+	g.scoring = params.Scoring
 	g.over = false
 	g.haveHuman = g.com1 == nil || g.com2 == nil
 	g.counterBlack, g.counterWhite = newCounterText()
@@ -137,12 +166,28 @@ func New(a fyne.App, window fyne.Window, menu *fyne.Container, params Parameter,
 		theme.ContentRedoIcon(),
 		func() {
 			g.passBtn.Disable()
-			g.now = g.now.Opponent()
+			g.pass()
 			g.update(nullPoint)
 		},
 	)
 	g.passBtn.Disable()
 
+	resignBtn := widget.NewButtonWithIcon(
+		"resign",
+		theme.CancelIcon(),
+		func() {
+			now := g.rec.Turn()
+			if g.over || g.isBot(now) {
+				return
+			}
+			dialog.NewConfirm("confirm", now.String()+" resigns?", func(b bool) {
+				if b && g.rec.Resign(now) {
+					g.update(nullPoint)
+				}
+			}, window).Show()
+		},
+	)
+
 	restart := widget.NewButtonWithIcon(
 		"restart",
 		theme.MediaReplayIcon(),
@@ -165,6 +210,14 @@ func New(a fyne.App, window fyne.Window, menu *fyne.Container, params Parameter,
 		},
 	)
 
+	saveBtn := widget.NewButtonWithIcon(
+		"save",
+		theme.DocumentSaveIcon(),
+		func() {
+			g.save(params)
+		},
+	)
+
 	mainMenu := widget.NewButtonWithIcon(
 		"menu",
 		theme.HomeIcon(),
@@ -188,11 +241,35 @@ func New(a fyne.App, window fyne.Window, menu *fyne.Container, params Parameter,
 		counterTile,
 		nameText,
 		container.NewCenter(grid),
-		container.NewGridWithColumns(2, g.passBtn, restart),
-		container.NewGridWithColumns(2, editBtn, mainMenu),
+		container.NewGridWithColumns(3, g.passBtn, resignBtn, restart),
+		container.NewGridWithColumns(3, editBtn, saveBtn, mainMenu),
 	)
 }
 
+// newBuiltIn returns the built-in AI playing for the rules, the menu only lets it
+// play the sizes it supports
+func newBuiltIn(size int, lv builtinai.Level, rules board.Rules) computer {
+	ai, err := builtinai.NewEngine(size, lv)
+	if err != nil {
+		panic(err)
+	}
+	ai.SetRules(rules)
+	ai.SetThreads(0)
+	ai.SetInfo(builtinai.PrintInfo)
+	return ai
+}
+
+// tapped plays p for a human
+func (g *game) tapped(p board.Point) {
+	if g.isBot(g.rec.Turn()) {
+		return
+	}
+	if !g.play(p) {
+		return
+	}
+	g.update(p)
+}
+
 func (g *game) isBot(cl board.Color) bool {
 	if cl == board.BLACK {
 		return g.com1 != nil
@@ -202,91 +279,122 @@ func (g *game) isBot(cl board.Color) bool {
 }
 
 func (g *game) round() {
-	var out string
+	var p board.Point
 	var err error
 	defer g.cleanAndExit()
 	for !g.over {
-		if g.isBot(g.now) {
+		now := g.rec.Turn()
+		if g.isBot(now) {
 			start := time.Now()
-			if g.now == board.BLACK {
-				out, err = g.com1.Move(g.bd.String())
+			if now == board.BLACK {
+				p, err = g.com1.Move(g.rec.Position())
 			} else {
-				out, err = g.com2.Move(g.bd.String())
+				p, err = g.com2.Move(g.rec.Position())
+			}
+			if g.over { // the game was abandoned, Close stopped the search
+				break
 			}
 			spent := time.Since(start)
-			fmt.Println(g.now, "side spent:", spent)
-			if g.now == board.BLACK {
+			fmt.Println(now, "side spent:", spent)
+			if now == board.BLACK {
 				g.blackSpent += spent
 			} else {
 				g.whiteSpent += spent
 			}
+			if err == nil && !g.play(p) {
+				err = fmt.Errorf("%v side played %s, which is not valid", now, p.Algebraic())
+			}
 			if err != nil {
-				g.aiError(err)
+				g.aiError(now, err)
 				break
 			}
-			g.bd.PutStr(g.now, out)
-			g.now = g.now.Opponent()
-			g.update(board.StrToPoint(out))
+			g.update(p)
 		} else {
 			time.Sleep(time.Millisecond * 30)
 		}
 	}
 }
 
+// play and pass record the move along with the time spent on it
+func (g *game) play(p board.Point) bool {
+	if !g.rec.Play(p) {
+		return false
+	}
+	g.moveTimes = append(g.moveTimes, time.Since(g.moveStart))
+	return true
+}
+
+func (g *game) pass() {
+	if g.rec.Pass() {
+		g.moveTimes = append(g.moveTimes, time.Since(g.moveStart))
+	}
+}
+
 func (g *game) update(current board.Point) {
-	g.over = g.bd.IsOver()
-	count := g.showValidAndCount(current)
-	if count == 0 && !g.over {
-		if g.haveHuman {
-			// current side is human
-			if (g.now == board.BLACK && g.com1 == nil) || (g.now == board.WHITE && g.com2 == nil) {
-				dialog.NewInformation("info", "you have to pass", g.window).Show()
-				g.passBtn.Enable()
-			} else { // current is computer
+	g.moveStart = time.Now()
+	g.over = g.rec.IsOver()
+	g.showValidAndCount(current)
+	if g.rec.MustPass() {
+		if g.isBot(g.rec.Turn()) {
+			if g.haveHuman {
 				dialog.NewInformation("info", "computer have to pass\nit's your turn", g.window).Show()
-				g.now = g.now.Opponent()
-				g.update(nullPoint)
 			}
-		} else {
-			g.now = g.now.Opponent()
-			g.showValidAndCount(current)
+			g.pass()
+			g.update(current)
+			return
 		}
+		dialog.NewInformation("info", "you have to pass", g.window).Show()
+		g.passBtn.Enable()
 	}
 	g.refreshCounter()
 	if g.over {
 		g.gameOver()
 	}
-	fmt.Println(g.bd.String())
+	fmt.Println(g.rec.Board().String())
 }
 
 func (g *game) refreshCounter() {
-	blacks := g.bd.CountPieces(board.BLACK)
-	whites := g.bd.CountPieces(board.WHITE)
+	bd := g.rec.Board()
+	blacks := bd.CountPieces(board.BLACK)
+	whites := bd.CountPieces(board.WHITE)
 	g.counterBlack.Update(fmt.Sprintf("black: %2d", blacks))
 	g.counterWhite.Update(fmt.Sprintf("white: %2d", whites))
 }
 
 func (g *game) gameOver() {
 	var text string
-	winner := g.bd.Winner()
-	if winner == board.NONE {
+	end, winner := g.rec.End(), g.rec.Winner(g.rules)
+	switch {
+	case end.Forfeit():
+		text = fmt.Sprintf("%v won by %v", winner, end)
+	case winner == board.NONE:
 		text = "draw"
-	} else {
+	default:
 		text = winner.String() + " won"
 	}
+	if !end.Forfeit() {
+		black, white := g.rec.Board().FinalScore(g.scoring)
+		text += fmt.Sprintf(" %d-%d", black, white)
+	}
 	d := dialog.NewInformation("Game Over", text, g.window)
 	d.Resize(fyne.NewSize(250, 0))
 	d.Show()
-	fmt.Println("\ngame over")
+	fmt.Println("\ngame over:", end)
+	fmt.Println("transcript:", g.rec.Transcript())
 	fmt.Println("black total:", g.blackSpent, ", white total:", g.whiteSpent)
 }
 
 func (g *game) showValidAndCount(current board.Point) int {
 	count := 0
+	bd := g.rec.Board()
+	valid := make(map[board.Point]bool)
+	for _, p := range g.rec.ValidMoves() {
+		valid[p] = true
+	}
 	for i, line := range g.units {
 		for j, u := range line {
-			cl := g.bd.AtXY(i, j)
-			if g.bd.IsValidPoint(g.now, board.NewPoint(i, j)) {
+			cl := bd.AtXY(i, j)
+			if valid[board.NewPoint(i, j)] {
 				u.SetResource(possible)
 				count++
 			} else {
@@ -300,10 +408,11 @@ func (g *game) showValidAndCount(current board.Point) int {
 	return count
 }
 
-func (g *game) aiError(err error) {
-	if !g.over {
+// aiError disqualifies the side of a computer that failed to give a valid move
+func (g *game) aiError(cl board.Color, err error) {
+	if !g.over && g.rec.Disqualify(cl) {
 		d := dialog.NewError(err, g.window)
-		d.SetOnClosed(func() { panic(err) })
+		d.SetOnClosed(func() { g.update(nullPoint) })
 		d.Show()
 	}
 }
@@ -318,35 +427,28 @@ func (g *game) cleanAndExit() {
 	}
 }
 
+// unit is a square of the board, a tap is passed on to the game it shows
 type unit struct {
-	g *game
 	widget.Icon
-	x, y  int
-	color board.Color
+	x, y     int
+	color    board.Color
+	size     fyne.Size
+	onTapped func(p board.Point)
 }
 
-func newUnit(g *game, cl board.Color, x, y int) *unit {
-	u := &unit{g: g, color: cl, x: x, y: y}
+func newUnit(cl board.Color, x, y int, size fyne.Size, onTapped func(p board.Point)) *unit {
+	u := &unit{color: cl, x: x, y: y, size: size, onTapped: onTapped}
 	u.setColor(cl)
 	u.ExtendBaseWidget(u)
 	return u
 }
 
 func (u *unit) Tapped(ev *fyne.PointEvent) {
-	if u.g.isBot(u.g.now) {
-		return
-	}
-	p := board.NewPoint(u.x, u.y)
-	if !u.g.bd.PutPoint(u.g.now, p) {
-		return
-	}
-
-	u.g.now = u.g.now.Opponent()
-	u.g.update(p)
+	u.onTapped(board.NewPoint(u.x, u.y))
 }
 
 func (u *unit) MinSize() fyne.Size {
-	return unitSize
+	return u.size
 }
 
 func (u *unit) setColor(cl board.Color) {
@@ -354,6 +456,12 @@ func (u *unit) setColor(cl board.Color) {
 		u.SetResource(blackImg)
 	} else if cl == board.WHITE {
 		u.SetResource(whiteImg)
+	} else if cl == board.RED {
+		u.SetResource(redImg)
+	} else if cl == board.BLUE {
+		u.SetResource(blueImg)
+	} else if cl == board.BORDER {
+		u.SetResource(blockedImg)
 	} else {
 		u.SetResource(noneImg)
 	}
@@ -364,5 +472,9 @@ func (u *unit) setColorCurrent(cl board.Color) {
 		u.SetResource(blackCurr)
 	} else if cl == board.WHITE {
 		u.SetResource(whiteCurr)
+	} else if cl == board.RED {
+		u.SetResource(redCurr)
+	} else if cl == board.BLUE {
+		u.SetResource(blueCurr)
 	}
 }
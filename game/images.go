@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"embed"
+	"image"
+	"image/color"
 	"image/png"
 	"io/ioutil"
 
@@ -18,6 +20,11 @@ var (
 	blackImg   *fyne.StaticResource
 	whiteImg   *fyne.StaticResource
 	noneImg    *fyne.StaticResource
+	blockedImg *fyne.StaticResource
+	redImg     *fyne.StaticResource
+	blueImg    *fyne.StaticResource
+	redCurr    *fyne.StaticResource
+	blueCurr   *fyne.StaticResource
 	possible   *fyne.StaticResource
 	blackCurr  *fyne.StaticResource
 	whiteCurr  *fyne.StaticResource
@@ -28,6 +35,11 @@ func init() {
 	blackImg = resourceFromBytes("img/black.webp")
 	whiteImg = resourceFromBytes("img/white.webp")
 	noneImg = resourceFromBytes("img/none.webp")
+	blockedImg = blockedResource("img/none.webp")
+	redImg = tintedResource("img/white.webp", "red.png", red)
+	blueImg = tintedResource("img/white.webp", "blue.png", blue)
+	redCurr = tintedResource("img/whiteCurrent.webp", "redCurrent.png", red)
+	blueCurr = tintedResource("img/whiteCurrent.webp", "blueCurrent.png", blue)
 	possible = resourceFromBytes("img/possible.webp")
 	blackCurr = resourceFromBytes("img/blackCurrent.webp")
 	whiteCurr = resourceFromBytes("img/whiteCurrent.webp")
@@ -39,8 +51,38 @@ func resourceFromBytes(path string) *fyne.StaticResource {
 	return fyne.NewStaticResource(path, cont)
 }
 
+// the share of each channel kept by tintedResource, in thirds
+var (
+	red  = [3]uint32{3, 1, 1}
+	blue = [3]uint32{1, 1, 3}
+	dark = [3]uint32{1, 1, 1}
+)
+
+// blockedResource is the empty square darkened, there is no image for blocked squares
+func blockedResource(path string) *fyne.StaticResource {
+	return tintedResource(path, "blocked.png", dark)
+}
+
+// tintedResource scales the channels of an image, the Rolit discs are tinted white ones
+func tintedResource(path, name string, tint [3]uint32) *fyne.StaticResource {
+	img := decodeFromFS(path)
+	tinted := image.NewRGBA(img.Bounds())
+	for y := img.Bounds().Min.Y; y < img.Bounds().Max.Y; y++ {
+		for x := img.Bounds().Min.X; x < img.Bounds().Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			r, g, b = r*tint[0]/3, g*tint[1]/3, b*tint[2]/3
+			tinted.Set(x, y, color.RGBA64{uint16(r), uint16(g), uint16(b), uint16(a)})
+		}
+	}
+	return fyne.NewStaticResource(name, encodePNG(tinted))
+}
+
 // fyne didn't support webp so convert to png first
 func bytesFromFS(path string) (cont []byte) {
+	return encodePNG(decodeFromFS(path))
+}
+
+func decodeFromFS(path string) image.Image {
 	f, err := source.Open(path)
 	if err != nil {
 		panic(err)
@@ -49,8 +91,12 @@ func bytesFromFS(path string) (cont []byte) {
 	if err != nil {
 		panic(err)
 	}
+	return img
+}
+
+func encodePNG(img image.Image) (cont []byte) {
 	buffer := new(bytes.Buffer)
-	err = png.Encode(buffer, img)
+	err := png.Encode(buffer, img)
 	if err != nil {
 		panic(err)
 	}
@@ -3,6 +3,8 @@ package game
 import (
 	"othello/board"
 	"othello/builtinai"
+	"othello/ggf"
+	"strings"
 )
 
 type Agent int
@@ -22,6 +24,31 @@ type Parameter struct {
 	BlackAILevel builtinai.Level
 	WhiteAILevel builtinai.Level
 	GoesFirst    board.Color
+	Rules        board.Rules
+	Scoring      board.Scoring
+
+	// the starting discs of a new board, or a whole starting position
+	// to use instead when Start is set, GoesFirst is the side to move in it
+	Opening board.Opening
+	Start   *board.Board
+
+	// squares blocked at random on a new board
+	Blocks int
+
+	// a loaded game to continue instead of starting a new one, and its moves
+	// with the times and evaluations it was saved with
+	Record      *board.Game
+	RecordMoves []ggf.Move
+
+	// 3 or 4 for a game of Rolit, red and blue take the seats after black and white,
+	// anything else plays othello
+	Players     int
+	RedAgent    Agent
+	BlueAgent   Agent
+	RedPath     string
+	BluePath    string
+	RedAILevel  builtinai.Level
+	BlueAILevel builtinai.Level
 }
 
 func NewParam() Parameter {
@@ -33,6 +60,75 @@ func NewParam() Parameter {
 	}
 }
 
+// BuiltInCanPlay reports whether the built-in AI can play the game the parameters
+// start on a board of size x size
+func (params Parameter) BuiltInCanPlay(size int) bool {
+	switch {
+	case params.Record != nil:
+		return builtinai.SupportBoard(params.Record.Board())
+	case params.Start != nil:
+		return builtinai.SupportBoard(params.Start)
+	default:
+		return params.Blocks == 0 && builtinai.SupportSize(size)
+	}
+}
+
+// IsRolit reports whether the game is Rolit rather than othello
+func (params Parameter) IsRolit() bool {
+	return params.Players >= 3 && params.Players <= board.MaxPlayers
+}
+
+// seat returns who plays cl
+func (params Parameter) seat(cl board.Color) (Agent, builtinai.Level, string) {
+	switch cl {
+	case board.BLACK:
+		return params.BlackAgent, params.BlackAILevel, params.BlackPath
+	case board.WHITE:
+		return params.WhiteAgent, params.WhiteAILevel, params.WhitePath
+	case board.RED:
+		return params.RedAgent, params.RedAILevel, params.RedPath
+	case board.BLUE:
+		return params.BlueAgent, params.BlueAILevel, params.BluePath
+	default:
+		return AgentNone, builtinai.LV_ONE, ""
+	}
+}
+
 func (params Parameter) AllSelected() bool {
-	return params.BlackAgent != AgentNone && params.WhiteAgent != AgentNone
+	if params.BlackAgent == AgentNone || params.WhiteAgent == AgentNone {
+		return false
+	}
+	if params.IsRolit() {
+		for _, cl := range board.Seats[:params.Players] {
+			if agent, _, _ := params.seat(cl); agent == AgentNone {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func agentName(agent Agent, lv builtinai.Level, path string) string {
+	switch agent {
+	case AgentHuman:
+		return "human"
+	case AgentBuiltIn:
+		return "AI: " + lv.String()
+	default:
+		path := strings.Split(path, "/")
+		return "AI: " + path[len(path)-1]
+	}
+}
+
+// Name returns who plays cl
+func (params Parameter) Name(cl board.Color) string {
+	return agentName(params.seat(cl))
+}
+
+func (params Parameter) BlackName() string {
+	return agentName(params.BlackAgent, params.BlackAILevel, params.BlackPath)
+}
+
+func (params Parameter) WhiteName() string {
+	return agentName(params.WhiteAgent, params.WhiteAILevel, params.WhitePath)
 }
@@ -0,0 +1,223 @@
+package game
+
+import (
+	"fmt"
+	"othello/board"
+	"othello/builtinai"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// rolitGame is a game of Rolit, one seat per color in turn order
+type rolitGame struct {
+	window fyne.Window
+	rec    *board.Rolit
+	units  [][]*unit
+
+	counters []Text
+
+	// the computer of every seat, nil for a human
+	coms  []computer
+	spent []time.Duration
+
+	over bool
+}
+
+// NewRolit starts a game of Rolit for params.Players on a new board of size x size
+func NewRolit(a fyne.App, window fyne.Window, menu *fyne.Container, params Parameter, size int) *fyne.Container {
+	g := &rolitGame{}
+	g.rec = board.NewRolit(board.NewRolitBoard(size, params.Players), params.Players)
+	grid, units := newGrid(g.rec.Board(), g.tapped)
+
+	players := g.rec.Players()
+	g.coms = make([]computer, len(players))
+	g.spent = make([]time.Duration, len(players))
+	counterTile := container.NewGridWithColumns(len(players))
+	nameTile := container.NewGridWithColumns(len(players))
+	for i, cl := range players {
+		agent, lv, path := params.seat(cl)
+		switch agent {
+		case AgentBuiltIn:
+			ai := builtinai.NewRolitAI(len(players), lv)
+			ai.SetInfo(builtinai.PrintInfo)
+			g.coms[i] = ai
+		case AgentExternal:
+			g.coms[i] = newCom(path)
+		}
+
+		counter := NewText("", nameTextSize, fyne.TextAlignCenter)
+		g.counters = append(g.counters, counter)
+		counterTile.Add(counter.CanvasText())
+		name := NewText(params.Name(cl), nameTextSize, fyne.TextAlignCenter)
+		name.SetMaxSize(window.Canvas().Size().Width / float32(len(players)))
+		nameTile.Add(name.CanvasText())
+	}
+
+	g.window = window
+	g.units = units
+
+	restart := widget.NewButtonWithIcon(
+		"restart",
+		theme.MediaReplayIcon(),
+		func() {
+			dialog.NewConfirm("confirm", "restart?", func(b bool) {
+				if b {
+					g.cleanAndExit()
+					window.SetContent(NewRolit(a, window, menu, params, size))
+				}
+			}, window).Show()
+		},
+	)
+
+	mainMenu := widget.NewButtonWithIcon(
+		"menu",
+		theme.HomeIcon(),
+		func() {
+			dialog.NewConfirm("confirm", "return to menu?", func(b bool) {
+				if b {
+					g.cleanAndExit()
+					menu.Show()
+					window.SetContent(menu)
+				}
+			}, window).Show()
+		},
+	)
+
+	if g.haveBot() {
+		go g.round()
+	}
+	g.update(nullPoint)
+
+	return container.NewVBox(
+		counterTile,
+		nameTile,
+		container.NewCenter(grid),
+		container.NewGridWithColumns(2, restart, mainMenu),
+	)
+}
+
+func (g *rolitGame) haveBot() bool {
+	for _, c := range g.coms {
+		if c != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *rolitGame) com(cl board.Color) computer {
+	return g.coms[cl.Number()-1]
+}
+
+// tapped plays p for a human
+func (g *rolitGame) tapped(p board.Point) {
+	if g.over || g.com(g.rec.Turn()) != nil {
+		return
+	}
+	if !g.rec.Play(p) {
+		return
+	}
+	g.update(p)
+}
+
+func (g *rolitGame) round() {
+	defer g.cleanAndExit()
+	for !g.over {
+		now := g.rec.Turn()
+		c := g.com(now)
+		if c == nil {
+			time.Sleep(time.Millisecond * 30)
+			continue
+		}
+		start := time.Now()
+		p, err := c.Move(g.rec.Position())
+		if g.over { // the game was abandoned, Close stopped the search
+			break
+		}
+		spent := time.Since(start)
+		fmt.Println(now, "side spent:", spent)
+		g.spent[now.Number()-1] += spent
+		if err == nil && !g.rec.Play(p) {
+			err = fmt.Errorf("%v side played %s, which is not valid", now, p.Algebraic())
+		}
+		if err != nil {
+			// there is no forfeit with more than two players, the game just stops
+			g.over = true
+			dialog.NewError(err, g.window).Show()
+			break
+		}
+		g.update(p)
+	}
+}
+
+func (g *rolitGame) update(current board.Point) {
+	g.over = g.rec.IsOver()
+	g.showValid(current)
+	for i, score := range g.rec.Scores() {
+		g.counters[i].Update(fmt.Sprintf("%v: %2d", g.rec.Players()[i], score))
+	}
+	if g.over {
+		g.gameOver()
+	}
+	fmt.Println(g.rec.Board().String())
+}
+
+func (g *rolitGame) showValid(current board.Point) {
+	bd := g.rec.Board()
+	valid := make(map[board.Point]bool)
+	for _, p := range g.rec.ValidMoves() {
+		valid[p] = true
+	}
+	for i, line := range g.units {
+		for j, u := range line {
+			cl := bd.AtXY(i, j)
+			if valid[board.NewPoint(i, j)] {
+				u.SetResource(possible)
+			} else {
+				u.setColor(cl)
+			}
+			if current.X == i && current.Y == j {
+				u.setColorCurrent(cl)
+			}
+		}
+	}
+}
+
+func (g *rolitGame) gameOver() {
+	var names []string
+	for _, cl := range g.rec.Winners() {
+		names = append(names, cl.String())
+	}
+	var scores []string
+	for _, score := range g.rec.Scores() {
+		scores = append(scores, fmt.Sprint(score))
+	}
+	text := strings.Join(names, " and ") + " won "
+	if len(names) > 1 {
+		text = "draw between " + strings.Join(names, " and ") + " "
+	}
+	text += strings.Join(scores, "-")
+	d := dialog.NewInformation("Game Over", text, g.window)
+	d.Resize(fyne.NewSize(250, 0))
+	d.Show()
+	fmt.Println("\ngame over")
+	fmt.Println("transcript:", g.rec.Transcript())
+	for i, cl := range g.rec.Players() {
+		fmt.Println(cl, "total:", g.spent[i])
+	}
+}
+
+func (g *rolitGame) cleanAndExit() {
+	g.over = true
+	for _, c := range g.coms {
+		if c != nil {
+			c.Close()
+		}
+	}
+}
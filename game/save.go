@@ -0,0 +1,63 @@
+package game
+
+import (
+	"othello/board"
+	"othello/ggf"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/storage"
+)
+
+// Record returns the game played so far in GGF
+func (g *game) Record(params Parameter) (*ggf.Game, error) {
+	gg, err := ggf.FromRecord(g.rec)
+	if err != nil {
+		return nil, err
+	}
+	gg.Place = "othello"
+	gg.Date = time.Now().Format("2006.01.02_15:04:05.MST")
+	gg.Black = params.BlackName()
+	gg.White = params.WhiteName()
+	if params.Rules == board.Anti {
+		gg.Type += "a"
+	}
+	if end := g.rec.End(); end != board.NotOver && !end.Forfeit() {
+		black, white := g.rec.Board().FinalScore(params.Scoring)
+		gg.Result = ggf.FormatResult(black - white)
+	}
+	for i := range gg.Moves {
+		if i < len(g.moveTimes) {
+			gg.Moves[i].Time = g.moveTimes[i].Round(10 * time.Millisecond)
+		}
+		if i < len(g.loaded) {
+			gg.Moves[i].Eval, gg.Moves[i].HasEval = g.loaded[i].Eval, g.loaded[i].HasEval
+		}
+	}
+	return gg, nil
+}
+
+func (g *game) save(params Parameter) {
+	gg, err := g.Record(params)
+	if err != nil {
+		dialog.NewInformation("info", "GGF only stores square boards", g.window).Show()
+		return
+	}
+	d := dialog.NewFileSave(func(w fyne.URIWriteCloser, err error) {
+		if err != nil {
+			dialog.NewError(err, g.window).Show()
+			return
+		}
+		if w == nil {
+			return // cancelled
+		}
+		defer w.Close()
+		if err := ggf.Write(w, gg); err != nil {
+			dialog.NewError(err, g.window).Show()
+		}
+	}, g.window)
+	d.SetFilter(storage.NewExtensionFileFilter([]string{".ggf"}))
+	d.SetFileName("game.ggf")
+	d.Show()
+}
@@ -0,0 +1,447 @@
+// Package ggf reads and writes games in the Generic Game Format used by
+// the Othello game servers and most other Othello programs, such as
+//
+//	(;GM[Othello]PC[NECI:SERV]DT[2003.12.15_13:24:03.MST]PB[alice]PW[bob]
+//	RB[2197.01]RW[2199.72]TI[15:00//02:00]TY[8]RE[+18.000]
+//	BO[8 -------- -------- -------- ---O*--- ---*O--- -------- -------- -------- *]
+//	B[d3//0.01]W[c5/-2.00/3.5]B[PA];)
+//
+// A move is "square/eval/time", eval and time are optional and time is in seconds.
+package ggf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"othello/board"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Move struct {
+	board.Move
+
+	// evaluation given by the player, only meaningful when HasEval is set
+	Eval    float64
+	HasEval bool
+
+	// time the player spent on the move, 0 if unknown
+	Time time.Duration
+}
+
+type Game struct {
+	Place string // PC
+	Date  string // DT
+
+	Black       string // PB
+	White       string // PW
+	BlackRating string // RB
+	WhiteRating string // RW
+
+	// TI, clock/increment/extension such as "15:00//02:00"
+	TimeControl string
+
+	// TY, the board size optionally followed by variant letters, such as "8" or "10a"
+	Type string
+
+	// RE, black's disc difference such as "+18.000", optionally followed by
+	// ":r" for resignation, ":t" for timeout or ":s" for mutual agreement
+	Result string
+
+	Start board.Position // BO
+	Moves []Move
+
+	// tags this package does not know about, kept in their original order
+	Extra [][2]string
+}
+
+// FromRecord builds a game from a record, the Start and Moves fields are filled
+// in, and the Result if the game is over: with the empty squares given to the winner
+// when it was played out, and as a win by every square when it was forfeited.
+// GGF only has square boards.
+func FromRecord(rec *board.Game) (*Game, error) {
+	if !rec.Start().IsSquare() {
+		return nil, fmt.Errorf("ggf: a %dx%d board is not square", rec.Start().Width(), rec.Start().Height())
+	}
+	g := &Game{
+		Type:  strconv.Itoa(rec.Start().Size()),
+		Start: board.NewPosition(rec.Start(), rec.First()),
+	}
+	for _, m := range rec.Moves() {
+		g.Moves = append(g.Moves, Move{Move: m})
+	}
+	switch end := rec.End(); {
+	case end.Forfeit():
+		squares := rec.Start().Size() * rec.Start().Size()
+		if rec.Winner(board.Standard) == board.WHITE {
+			squares = -squares
+		}
+		g.Result = FormatResult(squares) + forfeitMark(end)
+	case end != board.NotOver:
+		black, white := rec.Board().FinalScore(board.EmptiesToWinner)
+		g.Result = FormatResult(black - white)
+	}
+	return g, nil
+}
+
+// GGF has no mark for an illegal move, it counts as a resignation
+func forfeitMark(end board.EndReason) string {
+	if end == board.Timeout {
+		return ":t"
+	}
+	return ":r"
+}
+
+// FormatResult formats black's disc difference as in the RE tag
+func FormatResult(diff int) string {
+	return fmt.Sprintf("%+.3f", float64(diff))
+}
+
+// Score returns black's disc difference in the RE tag
+func (g *Game) Score() (float64, error) {
+	s := g.Result
+	if i := strings.IndexByte(s, ':'); i >= 0 {
+		s = s[:i]
+	}
+	return strconv.ParseFloat(s, 64)
+}
+
+// Rules returns board.Anti when TY has the 'a' variant letter
+func (g *Game) Rules() board.Rules {
+	if strings.ContainsRune(strings.TrimLeft(g.Type, "0123456789"), 'a') {
+		return board.Anti
+	}
+	return board.Standard
+}
+
+// Record replays the moves from the starting position. Passes are played
+// when needed even if the game left them out. A game the Result says was
+// resigned or lost on time ends that way.
+func (g *Game) Record() (*board.Game, error) {
+	rec, _, err := g.RecordMoves()
+	return rec, err
+}
+
+// RecordMoves is Record along with the moves of the game lined up with those of
+// the record, a pass the game left out has neither time nor evaluation
+func (g *Game) RecordMoves() (*board.Game, []Move, error) {
+	rec := board.NewGameFrom(g.Start)
+	var moves []Move
+	for i, m := range g.Moves {
+		if m.Color != rec.Turn() && !m.Pass {
+			// a pass the game left out
+			moves = append(moves, Move{Move: board.NewPass(rec.Turn())})
+			rec.Pass()
+		}
+		if !rec.PlayMove(m.Move) {
+			return nil, nil, fmt.Errorf("move %d: %v %s is not valid", i+1, m.Color, m.Algebraic())
+		}
+		moves = append(moves, m)
+	}
+
+	if score, err := g.Score(); err == nil && score != 0 {
+		loser := board.BLACK
+		if score > 0 {
+			loser = board.WHITE
+		}
+		switch {
+		case strings.HasSuffix(g.Result, ":r"):
+			rec.Resign(loser)
+		case strings.HasSuffix(g.Result, ":t"):
+			rec.TimeOut(loser)
+		}
+	}
+	return rec, moves, nil
+}
+
+// Read reads every game of r, games usually come one per line but any
+// whitespace between them and between tags is accepted
+func Read(r io.Reader) ([]*Game, error) {
+	br := bufio.NewReader(r)
+	var games []*Game
+	for {
+		if err := skipSpace(br); err == io.EOF {
+			return games, nil
+		} else if err != nil {
+			return games, err
+		}
+		g, err := readGame(br)
+		if err != nil {
+			return games, fmt.Errorf("game %d: %v", len(games)+1, err)
+		}
+		games = append(games, g)
+	}
+}
+
+// Parse reads a single game
+func Parse(s string) (*Game, error) {
+	games, err := Read(strings.NewReader(s))
+	if err != nil {
+		return nil, err
+	}
+	if len(games) != 1 {
+		return nil, fmt.Errorf("found %d games, want 1", len(games))
+	}
+	return games[0], nil
+}
+
+func skipSpace(br *bufio.Reader) error {
+	for {
+		c, err := br.ReadByte()
+		if err != nil {
+			return err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' {
+			return br.UnreadByte()
+		}
+	}
+}
+
+func expect(br *bufio.Reader, want string) error {
+	for i := range want {
+		c, err := br.ReadByte()
+		if err != nil {
+			return fmt.Errorf("expected %q: %v", want, err)
+		}
+		if c != want[i] {
+			return fmt.Errorf("expected %q, found %q", want, c)
+		}
+	}
+	return nil
+}
+
+func readGame(br *bufio.Reader) (*Game, error) {
+	if err := expect(br, "(;"); err != nil {
+		return nil, err
+	}
+	g := &Game{}
+	for {
+		if err := skipSpace(br); err != nil {
+			return nil, fmt.Errorf("unexpected end of game: %v", err)
+		}
+		c, _ := br.ReadByte()
+		if c == ';' {
+			if err := expect(br, ")"); err != nil {
+				return nil, err
+			}
+			break
+		}
+
+		tag := []byte{c}
+		for {
+			c, err := br.ReadByte()
+			if err != nil {
+				return nil, fmt.Errorf("unexpected end of game: %v", err)
+			}
+			if c == '[' {
+				break
+			}
+			if c < 'A' || c > 'Z' {
+				return nil, fmt.Errorf("invalid tag name %q", string(tag)+string(c))
+			}
+			tag = append(tag, c)
+		}
+		value, err := br.ReadString(']')
+		if err != nil {
+			return nil, fmt.Errorf("unterminated tag %s", tag)
+		}
+		if err := g.setTag(string(tag), value[:len(value)-1]); err != nil {
+			return nil, err
+		}
+	}
+	if g.Start.Board == nil {
+		return nil, fmt.Errorf("missing BO tag")
+	}
+	return g, nil
+}
+
+func (g *Game) setTag(tag, value string) error {
+	switch tag {
+	case "GM":
+		if !strings.EqualFold(value, "othello") {
+			return fmt.Errorf("not an Othello game: %q", value)
+		}
+	case "PC":
+		g.Place = value
+	case "DT":
+		g.Date = value
+	case "PB":
+		g.Black = value
+	case "PW":
+		g.White = value
+	case "RB":
+		g.BlackRating = value
+	case "RW":
+		g.WhiteRating = value
+	case "TI":
+		g.TimeControl = value
+	case "TY":
+		g.Type = value
+	case "RE":
+		g.Result = value
+	case "BO":
+		pos, err := parseBoard(value)
+		if err != nil {
+			return err
+		}
+		g.Start = pos
+	case "B", "W":
+		m, err := parseMove(value)
+		if err != nil {
+			return err
+		}
+		m.Color = board.BLACK
+		if tag == "W" {
+			m.Color = board.WHITE
+		}
+		g.Moves = append(g.Moves, m)
+	default:
+		g.Extra = append(g.Extra, [2]string{tag, value})
+	}
+	return nil
+}
+
+// the BO tag is the size, the rows and the side to move: '-' empty, '*' black and 'O' white
+func parseBoard(s string) (board.Position, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return board.Position{}, fmt.Errorf("invalid BO %q", s)
+	}
+	size, err := strconv.Atoi(fields[0])
+	if err != nil || !board.ValidSize(size) {
+		return board.Position{}, fmt.Errorf("invalid board size in BO %q", s)
+	}
+	var first board.Color
+	switch fields[len(fields)-1] {
+	case "*":
+		first = board.BLACK
+	case "O":
+		first = board.WHITE
+	default:
+		return board.Position{}, fmt.Errorf("invalid side to move in BO %q", s)
+	}
+
+	squares := strings.Join(fields[1:len(fields)-1], "")
+	if len(squares) != size*size {
+		return board.Position{}, fmt.Errorf("BO has %d squares, want %d", len(squares), size*size)
+	}
+	squares = strings.NewReplacer("-", "+", "*", "X").Replace(squares)
+	bd, err := board.ParseBoard(squares)
+	if err != nil {
+		return board.Position{}, fmt.Errorf("invalid BO: %v", err)
+	}
+	return board.NewPosition(bd, first), nil
+}
+
+func formatBoard(pos board.Position) string {
+	bd := pos.Board
+	s := strings.NewReplacer("+", "-", "X", "*").Replace(bd.String())
+	var sb strings.Builder
+	sb.WriteString(strconv.Itoa(bd.Size()))
+	for i := 0; i < len(s); i += bd.Width() {
+		sb.WriteString(" " + s[i:i+bd.Width()])
+	}
+	if pos.ToMove == board.WHITE {
+		sb.WriteString(" O")
+	} else {
+		sb.WriteString(" *")
+	}
+	return sb.String()
+}
+
+func parseMove(s string) (Move, error) {
+	parts := strings.Split(s, "/")
+	p, pass, err := board.ParseMove(parts[0])
+	if err != nil {
+		return Move{}, err
+	}
+	m := Move{Move: board.Move{Point: p, Pass: pass}}
+	if len(parts) > 1 && parts[1] != "" {
+		if m.Eval, err = strconv.ParseFloat(parts[1], 64); err != nil {
+			return Move{}, fmt.Errorf("invalid eval in move %q", s)
+		}
+		m.HasEval = true
+	}
+	if len(parts) > 2 && parts[2] != "" {
+		if m.Time, err = parseTime(parts[2]); err != nil {
+			return Move{}, fmt.Errorf("invalid time in move %q", s)
+		}
+	}
+	return m, nil
+}
+
+// times are seconds, possibly with minutes and hours in front as in "1:05.5"
+func parseTime(s string) (time.Duration, error) {
+	var seconds float64
+	for _, part := range strings.Split(s, ":") {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, err
+		}
+		seconds = seconds*60 + v
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (m Move) String() string {
+	s := m.Algebraic()
+	if m.HasEval || m.Time != 0 {
+		s += "/"
+		if m.HasEval {
+			s += strconv.FormatFloat(m.Eval, 'f', 2, 64)
+		}
+		if m.Time != 0 {
+			s += "/" + strconv.FormatFloat(m.Time.Seconds(), 'f', 2, 64)
+		}
+	}
+	return s
+}
+
+// String returns the game in GGF on a single line
+func (g *Game) String() string {
+	var sb strings.Builder
+	tag := func(name, value string) {
+		if value != "" {
+			sb.WriteString(name + "[" + value + "]")
+		}
+	}
+
+	sb.WriteString("(;")
+	tag("GM", "Othello")
+	tag("PC", g.Place)
+	tag("DT", g.Date)
+	tag("PB", g.Black)
+	tag("PW", g.White)
+	tag("RB", g.BlackRating)
+	tag("RW", g.WhiteRating)
+	tag("TI", g.TimeControl)
+	tag("TY", g.Type)
+	tag("RE", g.Result)
+	for _, kv := range g.Extra {
+		tag(kv[0], kv[1])
+	}
+	tag("BO", formatBoard(g.Start))
+	for _, m := range g.Moves {
+		if m.Color == board.BLACK {
+			tag("B", m.String())
+		} else {
+			tag("W", m.String())
+		}
+	}
+	sb.WriteString(";)")
+	return sb.String()
+}
+
+// Write writes the games one per line
+func Write(w io.Writer, games ...*Game) error {
+	for _, g := range games {
+		if bd := g.Start.Board; !bd.IsSquare() {
+			return fmt.Errorf("ggf: a %dx%d board is not square", bd.Width(), bd.Height())
+		}
+		if _, err := fmt.Fprintln(w, g.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
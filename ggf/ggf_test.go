@@ -0,0 +1,159 @@
+package ggf
+
+import (
+	"io"
+	"os"
+	"othello/board"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readCorpus(t *testing.T) []*Game {
+	f, err := os.Open("testdata/games.ggf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	games, err := Read(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return games
+}
+
+func TestCorpus(t *testing.T) {
+	games := readCorpus(t)
+	if len(games) != 5 {
+		t.Fatal("got", len(games), "games")
+	}
+
+	sizes := []int{8, 6, 10, 4, 8}
+	for i, g := range games {
+		rec, err := g.Record()
+		if err != nil {
+			t.Fatal("game", i+1, err)
+		}
+		if g.Start.Board.Size() != sizes[i] {
+			t.Error("game", i+1, "size", g.Start.Board.Size())
+		}
+		score, err := g.Score()
+		if err != nil {
+			t.Error("game", i+1, err)
+		}
+		if bd := rec.Board(); bd.IsOver() {
+			if black, white := bd.FinalScore(board.EmptiesToWinner); float64(black-white) != score {
+				t.Error("game", i+1, "result", g.Result, "but the board says", black-white)
+			}
+		}
+
+		// writing and reading again gives the same game
+		again, err := Parse(g.String())
+		if err != nil || again.String() != g.String() {
+			t.Error("game", i+1, err, "\n", g.String(), "\n", again.String())
+		}
+	}
+
+	g := games[0]
+	if g.Black != "alice" || g.White != "bob" || g.TimeControl != "15:00//02:00" || g.Extra[0] != [2]string{"KM", "0"} {
+		t.Error("tags", g.Black, g.White, g.TimeControl, g.Extra)
+	}
+	if m := g.Moves[0]; m.Algebraic() != "e6" || !m.HasEval || m.Eval != -16.34 || m.Time != 26970*time.Millisecond {
+		t.Error("first move", m)
+	}
+	if m := games[1].Moves[len(games[1].Moves)-1]; m.Time != 61770*time.Millisecond {
+		t.Error("time in minutes", m.Time)
+	}
+	if games[0].Rules() != board.Standard || (&Game{Type: "8a"}).Rules() != board.Anti {
+		t.Error("rules of", games[0].Type)
+	}
+	rec, _ := games[4].Record()
+	if g, _ := FromRecord(rec); rec.End() != board.Resignation || rec.Winner(board.Standard) != board.WHITE || g.Result != "-64.000:r" {
+		t.Error("resigned game", rec.End())
+	}
+	if g := games[4]; g.Start.ToMove != board.WHITE || g.Moves[0].Color != board.WHITE || !strings.HasSuffix(g.Result, ":r") {
+		t.Error("custom start", g)
+	}
+}
+
+func TestFromRecord(t *testing.T) {
+	rec, err := board.ParseTranscript(board.NewBoard(8), board.BLACK, "c4c3c2b4a5f4g4c5d6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := FromRecord(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "(;GM[Othello]TY[8]RE[+64.000]BO[8 -------- -------- -------- ---O*--- ---*O--- -------- -------- -------- *]B[c4]W[c3]B[c2]W[b4]B[a5]W[f4]B[g4]W[c5]B[d6];)"
+	if g.String() != want {
+		t.Error("\n", g.String(), "\n", want)
+	}
+
+	// GGF has no rectangular boards
+	pos, err := board.ParseLayout("++++++/++OX++/++XO++/++++++")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := FromRecord(board.NewGameFrom(pos)); err == nil {
+		t.Error("a 6x4 record was accepted")
+	}
+	if err := Write(io.Discard, &Game{Start: pos}); err == nil {
+		t.Error("a 6x4 game was written")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, s := range []string{
+		"",
+		"(;GM[Chess]BO[8 -------- -------- -------- ---O*--- ---*O--- -------- -------- -------- *];)",
+		"(;GM[Othello];)",
+		"(;GM[Othello]BO[8 -------- *];)",
+		"(;GM[Othello]BO[8 -------- -------- -------- ---O*--- ---*O--- -------- -------- -------- *]B[z9];)",
+		"(;GM[Othello]BO[8 -------- -------- -------- ---O*--- ---*O--- -------- -------- -------- *]B[d3",
+	} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("Parse(%q) should fail", s)
+		}
+	}
+}
+
+func TestRecordMoves(t *testing.T) {
+	// black has to pass after these on 4x4
+	rec, err := board.ParseTranscript(board.NewBoard(4), board.BLACK, "b1a1d3d4c4b4a3d2c1a2pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec.Play(rec.ValidMoves()[0])
+	g, err := FromRecord(rec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g.Moves = append(g.Moves[:10], g.Moves[11:]...)
+	for i := range g.Moves {
+		g.Moves[i].Time = time.Duration(i+1) * time.Second
+		g.Moves[i].Eval, g.Moves[i].HasEval = float64(i), true
+	}
+
+	again, moves, err := g.RecordMoves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(moves) != again.Ply() || again.Ply() != rec.Ply() {
+		t.Fatal(len(moves), "moves for", again.Ply(), "plies")
+	}
+	for i, m := range moves {
+		var want Move
+		switch {
+		case i == 10:
+			want = Move{Move: board.NewPass(board.BLACK)}
+		case i > 10:
+			want = g.Moves[i-1]
+		default:
+			want = g.Moves[i]
+		}
+		if m != want || m.Move != again.Moves()[i] {
+			t.Error("move", i, m, "want", want)
+		}
+	}
+}
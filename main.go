@@ -1,10 +1,14 @@
 package main
 
 import (
+	"fmt"
+	"os"
 	"othello/board"
 	"othello/builtinai"
 	"othello/game"
+	"othello/ggf"
 	"othello/othellotheme"
+	"strings"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
@@ -41,18 +45,17 @@ func main() {
 
 		blackCard *widget.Card
 		whiteCard *widget.Card
+		redCard   *widget.Card
+		blueCard  *widget.Card
 		all       *widget.Card
 		center    *widget.Card
 		goesFirst *widget.Card
 
-		selection1 *widget.Select
-		selection2 *widget.Select
-
-		sizeSelect *widget.RadioGroup
-		order      *widget.RadioGroup
-
-		levelSelect1 *widget.Select
-		levelSelect2 *widget.Select
+		sizeSelect    *widget.RadioGroup
+		order         *widget.RadioGroup
+		openingSelect *widget.Select
+		rulesSelect   *widget.Select
+		scoringSelect *widget.Select
 
 		playButton *widget.Button
 
@@ -60,114 +63,43 @@ func main() {
 		menu *fyne.Container
 	)
 
-	levels := []string{
-		builtinai.LV_ONE.String(),
-		builtinai.LV_TWO.String(),
-		builtinai.LV_THREE.String(),
-		builtinai.LV_FOUR.String(),
-		builtinai.LV_FIVE.String(),
+	updatePlay := func() {
+		if playButton == nil {
+			return // still building the menu
+		}
+		if params.AllSelected() {
+			playButton.Enable()
+		} else {
+			playButton.Disable()
+		}
+	}
+	blackCard = newSeatCard(ui, "black side", &params.BlackAgent, &params.BlackPath, &params.BlackAILevel, updatePlay)
+	whiteCard = newSeatCard(ui, "white side", &params.WhiteAgent, &params.WhitePath, &params.WhiteAILevel, updatePlay)
+	redCard = newSeatCard(ui, "red side", &params.RedAgent, &params.RedPath, &params.RedAILevel, updatePlay)
+	blueCard = newSeatCard(ui, "blue side", &params.BlueAgent, &params.BluePath, &params.BlueAILevel, updatePlay)
+	redCard.Hide()
+	blueCard.Hide()
+
+	top = container.NewGridWithColumns(2, blackCard, whiteCard, redCard, blueCard)
+
+	var sizes []string
+	for size := board.MinSize; size <= board.MaxSize; size += 2 {
+		sizes = append(sizes, fmt.Sprintf("%dx%d", size, size))
 	}
-	levelSelect1 = widget.NewSelect(
-		levels,
-		func(s string) {
-			params.BlackAILevel = builtinai.Level(levelSelect1.SelectedIndex())
-		},
-	)
-	levelSelect2 = widget.NewSelect(
-		levels,
-		func(s string) {
-			params.WhiteAILevel = builtinai.Level(levelSelect2.SelectedIndex())
-		},
-	)
-
-	selection1 = widget.NewSelect(
-		[]string{"human", "built-in AI", "external AI"},
-
-		func(s string) {
-			if s == "external AI" {
-				dir, err := fDialog.File().Load()
-				if err == nil {
-					params.BlackPath = dir
-					params.BlackAgent = game.AgentExternal
-					if params.AllSelected() {
-						playButton.Enable()
-					}
-				}
-			} else if s == "human" {
-				params.BlackAgent = game.AgentHuman
-			} else {
-				params.BlackAgent = game.AgentBuiltIn
-				d := dialog.NewCustom("select AI level", "  ok  ", levelSelect1, ui)
-				d.Resize(selectDialogSize)
-				d.Show()
-			}
-			if params.AllSelected() {
-				playButton.Enable()
-			}
-		},
-	)
-
-	subtitle1 := game.NewText("black side", cardTextSize, fyne.TextAlignCenter)
-	blackCard = widget.NewCard(
-		"",
-		"",
-		container.NewVBox(
-			subtitle1.CanvasText(),
-			container.NewCenter(selection1),
-		),
-	)
-
-	selection2 = widget.NewSelect(
-		[]string{"human", "built-in AI", "external AI"},
-
-		func(s string) {
-			if s == "external AI" {
-				dir, err := fDialog.File().Load()
-				if err == nil {
-					params.WhitePath = dir
-					params.WhiteAgent = game.AgentExternal
-					if params.AllSelected() {
-						playButton.Enable()
-					}
-				}
-			} else if s == "human" {
-				params.WhiteAgent = game.AgentHuman
-			} else {
-				params.WhiteAgent = game.AgentBuiltIn
-				d := dialog.NewCustom("select AI level", "  ok  ", levelSelect2, ui)
-				d.Resize(selectDialogSize)
-				d.Show()
-			}
-			if params.AllSelected() {
-				playButton.Enable()
-			}
-		},
-	)
-
-	subtitle2 := game.NewText("white side", cardTextSize, fyne.TextAlignCenter)
-	whiteCard = widget.NewCard(
-		"",
-		"",
-		container.NewVBox(
-			subtitle2.CanvasText(),
-			container.NewCenter(selection2),
-		),
-	)
-
-	top = container.NewGridWithColumns(2, blackCard, whiteCard)
-
 	sizeSelect = widget.NewRadioGroup(
-		[]string{"6x6", "8x8"},
+		sizes,
 
 		func(s string) {
-			if s == "8x8" {
-				boardSize = 8
-			} else {
-				boardSize = 6
+			fmt.Sscanf(s, "%d", &boardSize)
+			// a loaded game or position has its own size
+			params.Record, params.RecordMoves = nil, nil
+			if params.Start != nil && (params.Start.Width() != boardSize || params.Start.Height() != boardSize) {
+				openingSelect.SetSelected(board.Diagonal.String())
 			}
 		},
 	)
 	sizeSelect.SetSelected("6x6")
+	sizeSelect.Horizontal = true
 	sizeSelect.Required = true
 
 	subtitle3 := game.NewText("board size", cardTextSize, fyne.TextAlignCenter)
@@ -193,10 +125,112 @@ func main() {
 	order.SetSelected("black first")
 	order.Required = true
 
+	const customOpening = "custom..."
+	openings := []string{board.Diagonal.String(), board.Parallel.String(), customOpening}
+	openingSelect = widget.NewSelect(openings, nil)
+	openingSelect.OnChanged = func(s string) {
+		switch s {
+		case board.Diagonal.String():
+			params.Opening, params.Start = board.Diagonal, nil
+		case board.Parallel.String():
+			params.Opening, params.Start = board.Parallel, nil
+		case customOpening:
+			path, err := fDialog.File().Load()
+			if err != nil {
+				openingSelect.SetSelected(board.Diagonal.String())
+				return
+			}
+			pos, err := loadStart(path, boardSize)
+			if err != nil {
+				dialog.NewError(err, ui).Show()
+				openingSelect.SetSelected(board.Diagonal.String())
+				return
+			}
+			// set first, so the size radio doesn't take the previous start for
+			// one of another size and reset the opening
+			params.Start = pos.Board
+			// the size radio can't show a rectangle, the start keeps its own size
+			if pos.Board.IsSquare() {
+				size := pos.Board.Size()
+				sizeSelect.SetSelected(fmt.Sprintf("%dx%d", size, size))
+			}
+			if pos.ToMove == board.BLACK {
+				order.SetSelected("black first")
+			} else {
+				order.SetSelected("white first")
+			}
+		}
+	}
+	openingSelect.SetSelected(board.Diagonal.String())
+
+	blocks := []string{"no blocks", "4 blocks", "8 blocks"}
+	blocksSelect := widget.NewSelect(blocks, func(s string) {
+		params.Blocks = 0
+		fmt.Sscanf(s, "%d", &params.Blocks)
+	})
+	blocksSelect.SetSelected(blocks[0])
+
+	// Rolit seats red and blue after black and white, the othello settings don't apply to it
+	playerCounts := []string{"2 players", "3 players (rolit)", "4 players (rolit)"}
+	playersSelect := widget.NewSelect(playerCounts, func(s string) {
+		fmt.Sscanf(s, "%d", &params.Players)
+		if params.Players >= 3 {
+			redCard.Show()
+		} else {
+			redCard.Hide()
+		}
+		if params.Players >= 4 {
+			blueCard.Show()
+		} else {
+			blueCard.Hide()
+		}
+		for _, w := range []fyne.Disableable{order, openingSelect, rulesSelect, scoringSelect, blocksSelect} {
+			if params.IsRolit() {
+				w.Disable()
+			} else {
+				w.Enable()
+			}
+		}
+		updatePlay()
+		if menu != nil {
+			ui.Resize(menu.MinSize().Max(initWinSize))
+		}
+	})
+
+	var ruleNames []string
+	for _, r := range board.AllRules {
+		ruleNames = append(ruleNames, r.String())
+	}
+	rulesSelect = widget.NewSelect(ruleNames, func(s string) {
+		for _, r := range board.AllRules {
+			if r.String() == s {
+				params.Rules = r
+			}
+		}
+	})
+	rulesSelect.SetSelected(board.Standard.String())
+
+	// what the empty squares of a game that ends early count for
+	var scoringNames []string
+	for _, sc := range board.AllScorings {
+		scoringNames = append(scoringNames, sc.String())
+	}
+	scoringSelect = widget.NewSelect(scoringNames, func(s string) {
+		for _, sc := range board.AllScorings {
+			if sc.String() == s {
+				params.Scoring = sc
+			}
+		}
+	})
+	scoringSelect.SetSelected(board.EmptiesToWinner.String())
+
+	// the settings it disables exist by now
+	playersSelect.SetSelected(playerCounts[0])
+
 	goesFirst = widget.NewCard(
 		"",
 		"",
-		container.NewCenter(order),
+		container.NewCenter(container.NewHBox(order, container.NewVBox(openingSelect, rulesSelect, scoringSelect, blocksSelect, playersSelect))),
 	)
 
 	ruleButton := widget.NewButtonWithIcon(
@@ -205,7 +239,35 @@ func main() {
 		func() {
 			dialog.NewInformation(
 				"rule",
-				"rules here",
+				"put a disc to flank the opponent's discs and flip them\n"+
+					"pass when there is no valid move, the game ends when neither side can move\n\n"+
+					"standard: the most discs wins\n"+
+					"anti: the fewest discs wins",
+				ui,
+			).Show()
+		},
+	)
+
+	loadButton := widget.NewButtonWithIcon(
+		"      load      ",
+		theme.FolderOpenIcon(),
+		func() {
+			path, err := fDialog.File().Filter("GGF", "ggf").Load()
+			if err != nil {
+				return
+			}
+			rec, moves, rules, err := loadRecord(path)
+			if err != nil {
+				dialog.NewError(err, ui).Show()
+				return
+			}
+			size := rec.Start().Size()
+			sizeSelect.SetSelected(fmt.Sprintf("%dx%d", size, size))
+			params.Record, params.RecordMoves = rec, moves
+			rulesSelect.SetSelected(rules.String())
+			dialog.NewInformation(
+				"info",
+				fmt.Sprintf("game loaded, %d moves played\nselect the players and play to continue", rec.Ply()),
 				ui,
 			).Show()
 		},
@@ -215,6 +277,21 @@ func main() {
 		"      play      ",
 		theme.MediaPlayIcon(),
 		func() {
+			if params.IsRolit() {
+				c := game.NewRolit(a, ui, menu, params, boardSize)
+				menu.Hide()
+				ui.SetContent(c)
+				return
+			}
+			builtIn := params.BlackAgent == game.AgentBuiltIn || params.WhiteAgent == game.AgentBuiltIn
+			if builtIn && !params.BuiltInCanPlay(boardSize) {
+				dialog.NewInformation(
+					"info",
+					"built-in AI only supports 6x6 and 8x8 without blocked squares",
+					ui,
+				).Show()
+				return
+			}
 			c := game.New(a, ui, menu, params, boardSize)
 			menu.Hide()
 			ui.SetContent(c)
@@ -232,7 +309,7 @@ func main() {
 			container.NewMax(top),
 			container.NewMax(center),
 			container.NewMax(goesFirst),
-			container.NewCenter(ruleButton),
+			container.NewCenter(container.NewHBox(ruleButton, loadButton)),
 			container.NewCenter(playButton),
 		),
 	)
@@ -244,3 +321,92 @@ func main() {
 	ui.SetContent(menu)
 	ui.ShowAndRun()
 }
+
+// loadRecord reads the first game of a GGF file, its moves and the rules it is played under
+func loadRecord(path string) (*board.Game, []ggf.Move, board.Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, board.Standard, err
+	}
+	defer f.Close()
+
+	games, err := ggf.Read(f)
+	if err != nil {
+		return nil, nil, board.Standard, err
+	}
+	if len(games) == 0 {
+		return nil, nil, board.Standard, fmt.Errorf("no game in %s", path)
+	}
+	rec, moves, err := games[0].RecordMoves()
+	return rec, moves, games[0].Rules(), err
+}
+
+// loadStart reads a starting position from a file, either a board string and the side
+// to move as sent to external AIs, a layout whose rows are separated by '/' or new
+// lines, or a transcript played from a new board of size
+func loadStart(path string, size int) (board.Position, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return board.Position{}, err
+	}
+	s := strings.TrimSpace(string(b))
+	if pos, err := board.ParsePosition(s); err == nil {
+		return pos, nil
+	}
+	if pos, err := board.ParseLayout(s); err == nil {
+		return pos, nil
+	}
+	rec, err := board.ParseTranscript(board.NewBoard(size), board.BLACK, s)
+	if err != nil {
+		return board.Position{}, fmt.Errorf("%s is neither a position nor a transcript: %v", path, err)
+	}
+	return rec.Position(), nil
+}
+
+// newSeatCard returns the card choosing who plays a side, the level of the built-in
+// AI is asked for in a dialog, changed is called after every choice
+func newSeatCard(ui fyne.Window, title string, agent *game.Agent, path *string, level *builtinai.Level, changed func()) *widget.Card {
+	levels := []string{
+		builtinai.LV_ONE.String(),
+		builtinai.LV_TWO.String(),
+		builtinai.LV_THREE.String(),
+		builtinai.LV_FOUR.String(),
+		builtinai.LV_FIVE.String(),
+	}
+	levelSelect := widget.NewSelect(levels, nil)
+	levelSelect.OnChanged = func(s string) {
+		*level = builtinai.Level(levelSelect.SelectedIndex())
+	}
+
+	selection := widget.NewSelect(
+		[]string{"human", "built-in AI", "external AI"},
+
+		func(s string) {
+			if s == "external AI" {
+				dir, err := fDialog.File().Load()
+				if err == nil {
+					*path = dir
+					*agent = game.AgentExternal
+				}
+			} else if s == "human" {
+				*agent = game.AgentHuman
+			} else {
+				*agent = game.AgentBuiltIn
+				d := dialog.NewCustom("select AI level", "  ok  ", levelSelect, ui)
+				d.Resize(selectDialogSize)
+				d.Show()
+			}
+			changed()
+		},
+	)
+
+	subtitle := game.NewText(title, cardTextSize, fyne.TextAlignCenter)
+	return widget.NewCard(
+		"",
+		"",
+		container.NewVBox(
+			subtitle.CanvasText(),
+			container.NewCenter(selection),
+		),
+	)
+}
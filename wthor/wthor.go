@@ -0,0 +1,246 @@
+// Package wthor reads the WTHOR database of the Fédération Française d'Othello.
+// A database is a .wtb file of games, usually one per year, and two files of
+// names the games refer to by number: WTHOR.JOU for the players and WTHOR.TRN
+// for the tournaments.
+//
+// Every file starts with a 16 byte header, numbers are little endian:
+//
+//	0  century, year, month and day the file was created, one byte each
+//	4  number of games, uint32
+//	8  number of names, uint16
+//	10 year of the games, uint16
+//	12 board size, 0 or 8 for 8x8
+//	13 1 for solitaire games
+//	14 depth of the theoretical scores
+//	15 reserved
+//
+// followed by fixed size records: 68 bytes per game, 20 bytes per player and 26
+// bytes per tournament. Names are NUL padded ISO-8859-1.
+package wthor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"othello/board"
+	"time"
+)
+
+const (
+	HeaderSize         = 16
+	GameSize           = 68
+	PlayerNameSize     = 20
+	TournamentNameSize = 26
+
+	// moves stored per game, a game that ends early is padded with zeros
+	MaxMoves = 60
+)
+
+type Header struct {
+	Created time.Time
+
+	Games int // N1, number of games of a .wtb file
+	Names int // N2, number of names of a .jou or .trn file
+	Year  int
+
+	Size      int
+	Solitaire bool
+	Depth     int // depth the theoretical scores were computed at
+}
+
+// Game is one game of a .wtb file. Passes are not stored, Record puts them back.
+type Game struct {
+	Year       int
+	Tournament int
+	Black      int
+	White      int
+
+	// black's discs at the end of the game as played, and with perfect play from
+	// Header.Depth empty squares on. Empty squares count for the winner.
+	BlackDiscs  int
+	Theoretical int
+
+	Moves []board.Point
+}
+
+func ReadHeader(r io.Reader) (Header, error) {
+	var b [HeaderSize]byte
+	if _, err := io.ReadFull(r, b[:]); err != nil {
+		return Header{}, fmt.Errorf("reading header: %v", err)
+	}
+	h := Header{
+		Games:     int(binary.LittleEndian.Uint32(b[4:])),
+		Names:     int(binary.LittleEndian.Uint16(b[8:])),
+		Year:      int(binary.LittleEndian.Uint16(b[10:])),
+		Size:      int(b[12]),
+		Solitaire: b[13] == 1,
+		Depth:     int(b[14]),
+	}
+	if h.Size == 0 {
+		h.Size = 8
+	}
+	if b[2] >= 1 && b[2] <= 12 && b[3] >= 1 && b[3] <= 31 {
+		h.Created = time.Date(int(b[0])*100+int(b[1]), time.Month(b[2]), int(b[3]), 0, 0, 0, 0, time.UTC)
+	}
+	return h, nil
+}
+
+// ReadGames reads a .wtb file, only 8x8 databases are supported
+func ReadGames(r io.Reader) (Header, []Game, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return h, nil, err
+	}
+	if h.Size != 8 {
+		return h, nil, fmt.Errorf("unsupported board size %d", h.Size)
+	}
+
+	games := make([]Game, 0, h.Games)
+	var b [GameSize]byte
+	for i := 0; i < h.Games; i++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			return h, games, fmt.Errorf("game %d: %v", i+1, err)
+		}
+		g, err := decodeGame(b[:])
+		if err != nil {
+			return h, games, fmt.Errorf("game %d: %v", i+1, err)
+		}
+		g.Year = h.Year
+		games = append(games, g)
+	}
+	return h, games, nil
+}
+
+func decodeGame(b []byte) (Game, error) {
+	g := Game{
+		Tournament:  int(binary.LittleEndian.Uint16(b[0:])),
+		Black:       int(binary.LittleEndian.Uint16(b[2:])),
+		White:       int(binary.LittleEndian.Uint16(b[4:])),
+		BlackDiscs:  int(b[6]),
+		Theoretical: int(b[7]),
+	}
+	for _, v := range b[8:] {
+		if v == 0 {
+			break
+		}
+		// 10 * row + column, both from 1
+		row, col := int(v/10), int(v%10)
+		if row < 1 || row > 8 || col < 1 || col > 8 {
+			return g, fmt.Errorf("invalid move %d", v)
+		}
+		g.Moves = append(g.Moves, board.NewPoint(col-1, row-1))
+	}
+	return g, nil
+}
+
+// ReadNames reads a .jou file with PlayerNameSize or a .trn file with TournamentNameSize,
+// names are indexed by the numbers games refer to them with
+func ReadNames(r io.Reader, size int) ([]string, error) {
+	h, err := ReadHeader(r)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, h.Names)
+	b := make([]byte, size)
+	for i := 0; i < h.Names; i++ {
+		if _, err := io.ReadFull(r, b); err != nil {
+			return names, fmt.Errorf("name %d: %v", i, err)
+		}
+		names = append(names, decodeName(b))
+	}
+	return names, nil
+}
+
+// names are ISO-8859-1, whose bytes are the first 256 code points
+func decodeName(b []byte) string {
+	var rs []rune
+	for _, c := range b {
+		if c == 0 {
+			break
+		}
+		rs = append(rs, rune(c))
+	}
+	return string(rs)
+}
+
+// Record replays the game from the starting position, passing whenever the side
+// to move has no valid move
+func (g *Game) Record() (*board.Game, error) {
+	rec := board.NewGame(board.NewBoard(8), board.BLACK)
+	for i, p := range g.Moves {
+		rec.Pass()
+		if !rec.Play(p) {
+			return nil, fmt.Errorf("move %d: %s is not valid for %v", i+1, p.Algebraic(), rec.Turn())
+		}
+	}
+	return rec, nil
+}
+
+// Database is a .wtb file together with the names its games refer to
+type Database struct {
+	Header      Header
+	Games       []Game
+	Players     []string
+	Tournaments []string
+}
+
+// Load reads a .wtb file and the WTHOR.JOU and WTHOR.TRN name files,
+// either name file may be empty to leave the names out
+func Load(wtb, jou, trn string) (*Database, error) {
+	db := &Database{}
+	err := readFile(wtb, func(r io.Reader) (err error) {
+		db.Header, db.Games, err = ReadGames(r)
+		return
+	})
+	if err != nil {
+		return nil, err
+	}
+	if jou != "" {
+		err := readFile(jou, func(r io.Reader) (err error) {
+			db.Players, err = ReadNames(r, PlayerNameSize)
+			return
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if trn != "" {
+		err := readFile(trn, func(r io.Reader) (err error) {
+			db.Tournaments, err = ReadNames(r, TournamentNameSize)
+			return
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+func readFile(path string, read func(io.Reader) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := read(f); err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	return nil
+}
+
+// Player returns the name of player id, or "?" when it is unknown
+func (db *Database) Player(id int) string {
+	if id < 0 || id >= len(db.Players) {
+		return "?"
+	}
+	return db.Players[id]
+}
+
+// Tournament returns the name of tournament id, or "?" when it is unknown
+func (db *Database) Tournament(id int) string {
+	if id < 0 || id >= len(db.Tournaments) {
+		return "?"
+	}
+	return db.Tournaments[id]
+}
@@ -0,0 +1,147 @@
+package wthor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"othello/board"
+	"testing"
+)
+
+func header(games, names, year int) []byte {
+	b := make([]byte, HeaderSize)
+	b[0], b[1], b[2], b[3] = 20, 24, 1, 15
+	binary.LittleEndian.PutUint32(b[4:], uint32(games))
+	binary.LittleEndian.PutUint16(b[8:], uint16(names))
+	binary.LittleEndian.PutUint16(b[10:], uint16(year))
+	b[14] = 22
+	return b
+}
+
+func encodeGame(tournament, black, white, discs, theoretical int, transcript string) []byte {
+	b := make([]byte, GameSize)
+	binary.LittleEndian.PutUint16(b[0:], uint16(tournament))
+	binary.LittleEndian.PutUint16(b[2:], uint16(black))
+	binary.LittleEndian.PutUint16(b[4:], uint16(white))
+	b[6], b[7] = byte(discs), byte(theoretical)
+	for i := 0; i < len(transcript); i += 2 {
+		p, _ := board.ParsePoint(transcript[i : i+2])
+		b[8+i/2] = byte((p.Y+1)*10 + p.X + 1)
+	}
+	return b
+}
+
+func encodeNames(size int, names ...string) []byte {
+	b := header(0, len(names), 0)
+	for _, name := range names {
+		rec := make([]byte, size)
+		for i, r := range []rune(name) {
+			rec[i] = byte(r)
+		}
+		b = append(b, rec...)
+	}
+	return b
+}
+
+const (
+	// black has to pass twice, and the shortest game where white is wiped out after 9 moves
+	passGame  = "d3e3f5c3e2f3g4d6b3g5c6c2h5e1e6d7c8g3g2b6c7f6c5c4a5a7b4g6b2d8d2b5f2h1f7f1a6a1e8a4b7f8d1a3g8g7h6c1a2f4a8h8h3b8g1h7b1e7h4h2"
+	shortGame = "c4c3c2b4a5f4g4c5d6"
+)
+
+func TestDatabase(t *testing.T) {
+	data := header(2, 0, 2024)
+	data = append(data, encodeGame(1, 2, 0, 23, 30, passGame)...)
+	data = append(data, encodeGame(0, 1, 2, 13, 13, shortGame)...)
+	h, games, err := ReadGames(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := &Database{
+		Header:      h,
+		Games:       games,
+		Players:     []string{"Tastet Marc", "Müller Jörg", "?"},
+		Tournaments: []string{"Championnat de France", "Paris Open"},
+	}
+
+	// names are stored in ISO-8859-1
+	players, err := ReadNames(bytes.NewReader(encodeNames(PlayerNameSize, db.Players...)), PlayerNameSize)
+	if err != nil || len(players) != 3 || players[1] != "Müller Jörg" {
+		t.Error(players, err)
+	}
+	tournaments, err := ReadNames(bytes.NewReader(encodeNames(TournamentNameSize, db.Tournaments...)), TournamentNameSize)
+	if err != nil || len(tournaments) != 2 || tournaments[1] != "Paris Open" {
+		t.Error(tournaments, err)
+	}
+
+	g := db.Games[0]
+	if db.Tournament(g.Tournament) != "Paris Open" || db.Player(g.Black) != "?" || db.Player(g.White) != "Tastet Marc" || db.Player(99) != "?" {
+		t.Error("names", g)
+	}
+	rec, err := g.Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	passes := 0
+	for _, m := range rec.Moves() {
+		if m.Pass {
+			passes++
+		}
+	}
+	if passes != 2 || rec.Transcript() != passGame || rec.Board().CountPieces(board.BLACK) != g.BlackDiscs {
+		t.Error(passes, rec.Transcript(), rec.Board().Visualize())
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	short := header(2, 0, 2024)
+	short = append(short, encodeGame(0, 1, 2, 13, 13, shortGame)...)
+	if _, games, err := ReadGames(bytes.NewReader(short)); err == nil || len(games) != 1 {
+		t.Error("truncated file", err)
+	}
+
+	tenByTen := header(0, 0, 2024)
+	tenByTen[12] = 10
+	if _, _, err := ReadGames(bytes.NewReader(tenByTen)); err == nil {
+		t.Error("10x10 should be rejected")
+	}
+
+	bad := append(header(1, 0, 2024), encodeGame(0, 1, 2, 13, 13, shortGame)...)
+	bad[HeaderSize+8] = 19
+	if _, _, err := ReadGames(bytes.NewReader(bad)); err == nil {
+		t.Error("move 19 should be rejected")
+	}
+
+	illegal := append(header(1, 0, 2024), encodeGame(0, 1, 2, 13, 13, "a1")...)
+	_, games, err := ReadGames(bytes.NewReader(illegal))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := games[0].Record(); err == nil {
+		t.Error("a1 should not be playable")
+	}
+}
+
+func TestShortGame(t *testing.T) {
+	data := header(1, 0, 2024)
+	data = append(data, encodeGame(3, 1, 2, 64, 64, shortGame)...)
+
+	h, games, err := ReadGames(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.Games != 1 || h.Year != 2024 || h.Size != 8 || h.Depth != 22 || h.Created.Year() != 2024 || h.Created.Day() != 15 {
+		t.Error("header", h)
+	}
+	g := games[0]
+	if g.Year != 2024 || g.Tournament != 3 || g.Black != 1 || g.White != 2 || g.BlackDiscs != 64 || g.Theoretical != 64 {
+		t.Error("game", g)
+	}
+	rec, err := g.Record()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bd := rec.Board()
+	if black, _ := bd.FinalScore(board.EmptiesToWinner); rec.Transcript() != shortGame || !bd.IsOver() || black != g.BlackDiscs {
+		t.Error(rec.Transcript(), bd.Visualize())
+	}
+}